@@ -0,0 +1,175 @@
+package vc
+
+import "fmt"
+
+// The set operations below treat Constraints as the set of versions they
+// match, ordered by Compare. A prerelease is therefore inside a range whenever
+// Compare places it between the bounds, e.g. 2.0.0-alpha is below 2.0.0.
+//
+// Under PrereleaseExclude, and under PrereleaseNPM without a prerelease
+// comparator, constraints match no prerelease at all. The set operations
+// then work on releases alone, e.g. the complement of >=1.0.0 under
+// PrereleaseNPM is <1.0.0, which matches no prerelease either. A range
+// holding prereleases only, such as >1.0.0 <1.0.1, is not recognized as
+// empty among releases.
+//
+// The set operations return ErrPrereleasePolicy for constraints under
+// PrereleaseNPM with a prerelease comparator, which admit prereleases by the
+// comparators of each group rather than by their order, and for constraints
+// under PrereleaseIncludeAll combined with ones that match releases only.
+// They return ErrArbitraryEquality for PEP 440 "===" comparators.
+
+// Intersect returns the constraints matched by versions that satisfy both c
+// and o.
+func (c *Constraints) Intersect(o *Constraints) (*Constraints, error) {
+	if err := orderedSets(c, o); err != nil {
+		return nil, err
+	}
+	return c.fromSet(c.set().intersect(o.set())), nil
+}
+
+// Union returns the constraints matched by versions that satisfy c or o.
+func (c *Constraints) Union(o *Constraints) (*Constraints, error) {
+	if err := orderedSets(c, o); err != nil {
+		return nil, err
+	}
+	return c.fromSet(c.set().union(o.set())), nil
+}
+
+// Complement returns the constraints matched by versions that do not
+// satisfy c.
+func (c *Constraints) Complement() (*Constraints, error) {
+	if err := orderedSets(c); err != nil {
+		return nil, err
+	}
+	return c.fromSet(c.set().complement()), nil
+}
+
+// IsSubsetOf tests if every version that satisfies c also satisfies o.
+func (c *Constraints) IsSubsetOf(o *Constraints) (bool, error) {
+	if err := orderedSets(c, o); err != nil {
+		return false, err
+	}
+	return len(c.set().intersect(o.set().complement())) == 0, nil
+}
+
+// Overlaps tests if at least one version satisfies both c and o.
+func (c *Constraints) Overlaps(o *Constraints) (bool, error) {
+	if err := orderedSets(c, o); err != nil {
+		return false, err
+	}
+	return len(c.set().intersect(o.set())) > 0, nil
+}
+
+// Equivalent tests if c and o are satisfied by exactly the same versions,
+// however they are spelled.
+func (c *Constraints) Equivalent(o *Constraints) (bool, error) {
+	if err := orderedSets(c, o); err != nil {
+		return false, err
+	}
+	return c.set().equal(o.set()), nil
+}
+
+// IsEmpty tests if no version can satisfy the constraints.
+func (c *Constraints) IsEmpty() (bool, error) {
+	if err := orderedSets(c); err != nil {
+		return false, err
+	}
+	return len(c.set()) == 0, nil
+}
+
+// Simplify returns the smallest constraints equivalent to c. Overlapping and
+// adjacent groups are merged, dominated comparators are dropped and every
+// group is reduced to at most a lower bound, an upper bound and the single
+// versions it excludes:
+//
+//	>=1.0.0 >=1.2.0 <3.0.0 || ^1.5  -->  >=1.2.0 <3.0.0
+//	>=1.0.0 <1.5.0 || >1.5.0 <2.0.0  -->  >=1.0.0 <2.0.0 !=1.5.0
+//
+// Unsatisfiable groups are removed, so constraints nothing can satisfy
+// simplify to no groups at all, written as ">=0.0.0 <0.0.0".
+//
+// Simplify silently returns c unchanged for constraints the set operations
+// reject: under PrereleaseNPM with a prerelease comparator, merging the
+// groups would change which prereleases they admit, and a PEP 440 "==="
+// comparator does not match a range.
+func (c *Constraints) Simplify() *Constraints {
+	if orderedSets(c) != nil {
+		return c
+	}
+	return c.fromSet(c.set())
+}
+
+// fromSet creates Constraints matching the versions of s that share the
+// version parser and prerelease policy of c. Under PrereleaseNPM, a set
+// bounded by a prerelease, which only PrereleaseExclude constraints may
+// contribute, is given PrereleaseExclude so that it still matches releases
+// only.
+func (c *Constraints) fromSet(s intervalSet) *Constraints {
+	policy := c.policy
+	if policy == PrereleaseNPM && s.hasPrerelease() {
+		policy = PrereleaseExclude
+	}
+	return &Constraints{constraints: s.groups(), newfn: c.newfn, policy: policy, syntax: c.syntax}
+}
+
+// orderedSets returns ErrPrereleasePolicy or ErrArbitraryEquality unless
+// the versions the constraints match are the ranges of their comparators,
+// either all of them or their releases only.
+func orderedSets(cs ...*Constraints) error {
+	for _, c := range cs {
+		if c.arbitrary() {
+			return fmt.Errorf("%w: %q", ErrArbitraryEquality, c.String())
+		}
+		if c.policy == PrereleaseNPM && c.hasPrerelease() {
+			return fmt.Errorf("%w: %q admits prereleases by comparator", ErrPrereleasePolicy, c.String())
+		}
+		if (c.policy == PrereleaseIncludeAll) != (cs[0].policy == PrereleaseIncludeAll) {
+			return fmt.Errorf("%w: %q matches prereleases, %q releases only", ErrPrereleasePolicy,
+				includeAll(c, cs[0]).String(), includeAll(cs[0], c).String())
+		}
+	}
+	return nil
+}
+
+// includeAll returns whichever of a and b is under PrereleaseIncludeAll.
+func includeAll(a, b *Constraints) *Constraints {
+	if a.policy == PrereleaseIncludeAll {
+		return a
+	}
+	return b
+}
+
+// hasPrerelease reports whether a comparator is on a prerelease.
+func (c *Constraints) hasPrerelease() bool {
+	for _, g := range c.constraints {
+		for _, v := range g {
+			if v.com.Prerelease() != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasPrerelease reports whether a bound of the set is a prerelease.
+func (s intervalSet) hasPrerelease() bool {
+	for _, i := range s {
+		if i.lo.v != nil && i.lo.v.Prerelease() != "" || i.hi.v != nil && i.hi.v.Prerelease() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// arbitrary reports whether a comparator compares versions as written.
+func (c *Constraints) arbitrary() bool {
+	for _, g := range c.constraints {
+		for _, v := range g {
+			if v.operator == pep440Arbitrary {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustSemverConstraint(t *testing.T, c string) *Constraints {
+	t.Helper()
+	con, err := NewConstraint(c, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	assert.NoError(t, err)
+	return con
+}
+
+// equivalent tests if two constraints match the same versions, failing the
+// test on an error.
+func equivalent(t *testing.T, a, b *Constraints) bool {
+	t.Helper()
+	ok, err := a.Equivalent(b)
+	assert.NoError(t, err)
+	return ok
+}
+
+func TestConstraintsIntersect(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{"^1.2", ">=1.5.0", ">=1.5.0 <2.0.0"},
+		{"^1.2 || ^3", "~1.4 || >=3.5", ">=1.4.0 <1.5.0 || >=3.5.0 <4.0.0"},
+		{"^1", "^2", ">=0.0.0 <0.0.0"},
+		{">=1.0.0 <=2.0.0", ">=2.0.0", "2.0.0"},
+		{">=1.0.0 <2.0.0", "!=1.5.0", ">=1.0.0 <2.0.0 !=1.5.0"},
+		{"<2.0.0", ">=2.0.0-alpha", ">=2.0.0-alpha <2.0.0"},
+		{"*", "<1.0.0", ">=0.0.0 <1.0.0"},
+	}
+
+	for _, tc := range tests {
+		a, b := mustSemverConstraint(t, tc.a), mustSemverConstraint(t, tc.b)
+		got, err := a.Intersect(b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, got.String(), "%s ∩ %s", tc.a, tc.b)
+		reversed, err := b.Intersect(a)
+		assert.NoError(t, err)
+		assert.True(t, equivalent(t, got, reversed))
+	}
+}
+
+func TestConstraintsUnion(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{"^1.2", "^1.5", ">=1.2.0 <2.0.0"},
+		{"^1", "^2", ">=1.0.0 <3.0.0"},
+		{"^1", "^3", ">=1.0.0 <2.0.0 || >=3.0.0 <4.0.0"},
+		{"<1.5.0", ">1.5.0", "!=1.5.0"},
+		{"<1.5.0", ">=1.5.0", "*"},
+		{">=1.0.0 <1.5.0", ">1.5.0 <2.0.0", ">=1.0.0 <2.0.0 !=1.5.0"},
+	}
+
+	for _, tc := range tests {
+		a, b := mustSemverConstraint(t, tc.a), mustSemverConstraint(t, tc.b)
+		got, err := a.Union(b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, got.String(), "%s ∪ %s", tc.a, tc.b)
+	}
+}
+
+func TestConstraintsComplement(t *testing.T) {
+	tests := []struct {
+		con      string
+		expected string
+	}{
+		{"^1.2", "<1.2.0 || >=2.0.0"},
+		{"=1.5.0", "!=1.5.0"},
+		{"!=1.5.0", "1.5.0"},
+		{">=1.0.0 <2.0.0 || >=3.0.0", "<1.0.0 || >=2.0.0 <3.0.0"},
+		{">=1.0.0 <2.0.0 !=1.5.0", "<1.0.0 || 1.5.0 || >=2.0.0"},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		got, err := c.Complement()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, got.String(), "¬%s", tc.con)
+		back, err := got.Complement()
+		assert.NoError(t, err)
+		assert.True(t, equivalent(t, back, c))
+		overlaps, err := got.Overlaps(c)
+		assert.NoError(t, err)
+		assert.False(t, overlaps)
+	}
+
+	none, err := mustSemverConstraint(t, "<1.0.0 || >=1.0.0").Complement()
+	assert.NoError(t, err)
+	empty, err := none.IsEmpty()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+	assert.Equal(t, ">=0.0.0 <0.0.0", none.String())
+}
+
+func TestConstraintsEmptyRoundTrip(t *testing.T) {
+	none, err := mustSemverConstraint(t, "^1").Intersect(mustSemverConstraint(t, "^2"))
+	assert.NoError(t, err)
+	parsed := mustSemverConstraint(t, none.String())
+	for _, v := range []string{"0.0.0", "0.0.0-alpha", "1.5.0"} {
+		ok, err := parsed.CheckString(v)
+		assert.NoError(t, err)
+		assert.False(t, ok, v)
+	}
+	assert.True(t, equivalent(t, none, parsed))
+}
+
+func TestConstraintsIsSubsetOf(t *testing.T) {
+	tests := []struct {
+		a      string
+		b      string
+		subset bool
+	}{
+		{"~1.4", "^1.2", true},
+		{"^1.2", "~1.4", false},
+		{"1.4.5", ">=1.0.0 <2.0.0", true},
+		{">=1.0.0 <2.0.0 !=1.5.0", ">=1.0.0 <2.0.0", true},
+		{">=1.0.0 <2.0.0", ">=1.0.0 <2.0.0 !=1.5.0", false},
+		{"^1 || ^2", ">=1.0.0 <3.0.0", true},
+		{">2.0.0 <1.0.0", "1.0.0", true},
+		{"2.0.0-beta.1", "^1.2", true},
+	}
+
+	for _, tc := range tests {
+		a, b := mustSemverConstraint(t, tc.a), mustSemverConstraint(t, tc.b)
+		subset, err := a.IsSubsetOf(b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.subset, subset, "%s ⊆ %s", tc.a, tc.b)
+	}
+}
+
+func TestConstraintsOverlaps(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		overlaps bool
+	}{
+		{"^1.2", "~1.4", true},
+		{"^1", "^2", false},
+		{"<=2.0.0", ">=2.0.0", true},
+		{"<2.0.0", ">=2.0.0", false},
+		{"!=1.5.0", "1.5.0", false},
+	}
+
+	for _, tc := range tests {
+		a, b := mustSemverConstraint(t, tc.a), mustSemverConstraint(t, tc.b)
+		overlaps, err := a.Overlaps(b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.overlaps, overlaps, "%s, %s", tc.a, tc.b)
+	}
+}
+
+func TestConstraintsEquivalent(t *testing.T) {
+	tests := []struct {
+		a          string
+		b          string
+		equivalent bool
+	}{
+		{"^1.2.3", ">=1.2.3 <2.0.0", true},
+		{"1.2.x", "~1.2", true},
+		{"^1 || ^2", ">=1 <3", true},
+		{"^1", "^1.0.1", false},
+		{"<1.5.0 || >1.5.0", "!=1.5.0", true},
+		{">2.0.0 <1.0.0", "^1 >=2", true},
+	}
+
+	for _, tc := range tests {
+		a, b := mustSemverConstraint(t, tc.a), mustSemverConstraint(t, tc.b)
+		assert.Equal(t, tc.equivalent, equivalent(t, a, b), "%s ≡ %s", tc.a, tc.b)
+	}
+}
+
+func TestConstraintsSetCheck(t *testing.T) {
+	a := mustSemverConstraint(t, "^1.2 || ^3")
+	b := mustSemverConstraint(t, "~1.4 || >=3.5")
+	c, err := a.Intersect(b)
+	assert.NoError(t, err)
+	c, err = c.Union(mustSemverConstraint(t, "=2.0.0"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ver   string
+		valid bool
+	}{
+		{"1.4.2", true},
+		{"1.5.0", false},
+		{"2.0.0", true},
+		{"3.4.0", false},
+		{"3.6.0", true},
+		{"4.0.0", false},
+	}
+
+	for _, tc := range tests {
+		ok, err := c.CheckString(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, ok, tc.ver)
+	}
+}
+
+func TestConstraintsSimplify(t *testing.T) {
+	tests := []struct {
+		con      string
+		expected string
+	}{
+		{">=1.0.0 >=1.2.0 <3.0.0 || ^1.5", ">=1.2.0 <3.0.0"},
+		{">=1.0.0 <1.5.0 || >1.5.0 <2.0.0", ">=1.0.0 <2.0.0 !=1.5.0"},
+		{"^1 || ^2 || ^3", ">=1.0.0 <4.0.0"},
+		{"^1 || 1.4.2 || ~1.7", ">=1.0.0 <2.0.0"},
+		{">1.0.0 >=1.0.0 <=3.0.0 <2.0.0", ">1.0.0 <2.0.0"},
+		{"!=1.0.0 !=1.0.0 >=0.5.0", ">=0.5.0 !=1.0.0"},
+		{"!=3.0.0 ^1", ">=1.0.0 <2.0.0"},
+		{">=1.0.0 <=1.0.0", "1.0.0"},
+		{">2.0.0 <1.0.0 || ^1", ">=1.0.0 <2.0.0"},
+		{">2.0.0 <1.0.0", ">=0.0.0 <0.0.0"},
+		{"<1.0.0 || >=1.0.0", "*"},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		got := c.Simplify()
+		assert.Equal(t, tc.expected, got.String(), tc.con)
+		assert.True(t, equivalent(t, got, c))
+	}
+}
+
+func TestConstraintsSetPolicy(t *testing.T) {
+	newc := func(con string, policy PrereleasePolicy) *Constraints {
+		c, err := NewConstraint(con, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		}, WithPrereleasePolicy(policy))
+		assert.NoError(t, err)
+		return c
+	}
+
+	// Under PrereleaseNPM a prerelease comparator admits prereleases of its
+	// release only, merging the groups into >=1.0.0 <3.0.0 would reject
+	// 2.0.0-rc.2.
+	npm := newc(">=1.0.0 <2.0.0 || >=2.0.0-rc.1 <3.0.0", PrereleaseNPM)
+	all := newc("*", PrereleaseNPM)
+	assert.Same(t, npm, npm.Simplify())
+	_, err := npm.Intersect(all)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = all.Union(npm)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = npm.Complement()
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = npm.IsSubsetOf(all)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = all.Overlaps(npm)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = npm.Equivalent(npm)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+	_, err = npm.IsEmpty()
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+
+	// Without prerelease comparators, or under PrereleaseExclude, only
+	// releases match and the operations work on them.
+	for _, policy := range []PrereleasePolicy{PrereleaseNPM, PrereleaseExclude} {
+		c := newc(">=1.0.0 <2.0.0 || >=1.5.0 <3.0.0", policy)
+		u, err := c.Union(newc(">=3.0.0 <4.0.0", policy))
+		assert.NoError(t, err)
+		assert.Equal(t, ">=1.0.0 <4.0.0", u.String())
+		assert.Equal(t, policy, u.policy)
+
+		comp, err := c.Complement()
+		assert.NoError(t, err)
+		assert.Equal(t, "<1.0.0 || >=3.0.0", comp.String())
+		ok, _ := comp.CheckString("0.9.0-alpha")
+		assert.False(t, ok)
+
+		assert.Equal(t, ">=1.0.0 <3.0.0", c.Simplify().String())
+
+		sub, err := c.IsSubsetOf(newc("^1 || ^2", policy))
+		assert.NoError(t, err)
+		assert.True(t, sub)
+	}
+
+	excl := newc(">=2.0.0-rc.1 <3.0.0", PrereleaseExclude)
+	i, err := newc("^2", PrereleaseNPM).Union(excl)
+	assert.NoError(t, err)
+	assert.Equal(t, PrereleaseExclude, i.policy)
+	ok, _ := i.CheckString("2.0.0-rc.2")
+	assert.False(t, ok)
+
+	// PrereleaseIncludeAll constraints match prereleases the others do not.
+	_, err = mustSemverConstraint(t, "*").Intersect(newc("^1", PrereleaseNPM))
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+}
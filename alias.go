@@ -0,0 +1,184 @@
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AliasResolver maps a name used in a constraint, e.g. "lts", to the
+// version or constraint it stands for, e.g. "1.22.4" or "^1.22". It reports
+// false for names it does not know.
+type AliasResolver func(name string) (string, bool)
+
+// WithAliasResolver resolves the names in an expression when it is parsed.
+// A name used on its own, as in "lts || >=2.3", is replaced by the
+// constraint it resolves to. A name after an operator, as in "^lts", must
+// resolve to a version, which is read with the rules of the dialect: >lts
+// is >=1.3.0 under DialectNPM when lts resolves to 1.2.
+func WithAliasResolver(r AliasResolver) Option {
+	return func(o *options) {
+		o.resolve = r
+	}
+}
+
+// WithAliases resolves the names in an expression from a map, see
+// WithAliasResolver.
+func WithAliases(aliases map[string]string) Option {
+	m := make(map[string]string, len(aliases))
+	for k, v := range aliases {
+		m[k] = v
+	}
+	return WithAliasResolver(func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	})
+}
+
+// aliasRegex matches a name, optionally after an operator, e.g. ^lts.
+var aliasRegex = regexp.MustCompile(`^(>=|<=|!=|>|<|=|\^|~|!)?([A-Za-z][A-Za-z0-9_\-]*)$`)
+
+// parseAliases parses a clause whose words may be aliases. Each alias is
+// parsed from the text it resolves to and joined with AND to the rest of
+// the clause. The comparators of an alias keep the alias as their source.
+func (p *parser) parseAliases(t token) (node, error) {
+	// Aliases are blanked out of the clause, so the offsets of the rest stay
+	// unchanged.
+	rest := []byte(t.text)
+	seps := " \t\n\r"
+	if p.opts.dialect == DialectCargo {
+		seps += ","
+	}
+	var nodes []node
+	for _, w := range clauseWords(t.text, seps) {
+		m := aliasRegex.FindStringSubmatch(w.text)
+		if m == nil {
+			continue
+		}
+		resolved, ok := p.opts.resolve(m[2])
+		if !ok {
+			continue
+		}
+		n, err := p.parseAlias(m[1], m[2], resolved, t.offset+w.offset+len(m[1]))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		for k := w.offset; k < w.offset+len(w.text); k++ {
+			rest[k] = ' '
+		}
+	}
+	if strings.Trim(string(rest), seps) != "" {
+		n, err := p.parseClause(string(rest), t.offset)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+
+	result := nodes[0]
+	for _, n := range nodes[1:] {
+		result = &andNode{left: result, right: n}
+	}
+	return result, nil
+}
+
+// parseAlias parses the text an alias found at offset resolves to.
+func (p *parser) parseAlias(op, name, resolved string, offset int) (node, error) {
+	for _, v := range p.resolving {
+		if v == name {
+			return nil, &ParseError{Input: p.input, Offset: offset, Token: name,
+				Reason: "alias refers to itself", Err: ErrInvalidConstraint}
+		}
+	}
+	src := op + name
+	// Errors in the resolved text are reported at the alias, keeping the
+	// sentinel error and the reason.
+	aliasError := func(err error) error {
+		reason, sentinel := "empty constraint", ErrInvalidConstraint
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			reason, sentinel = pe.Reason, pe.Err
+		}
+		return &ParseError{Input: p.input, Offset: offset, Token: name,
+			Reason: fmt.Sprintf("alias resolves to %q: %s", resolved, reason), Err: sentinel}
+	}
+
+	var n node
+	if op != "" {
+		// ^lts is parsed as ^ followed by the version lts resolves to, with
+		// the rules of the dialect.
+		ver := strings.TrimSpace(resolved)
+		if ver == "" || strings.ContainsAny(ver, " \t\n\r,|&()") {
+			return nil, aliasError(&ParseError{Reason: "not a version", Err: ErrInvalidConstraint})
+		}
+		var err error
+		if n, err = p.parseClause(op+ver, 0); err != nil {
+			return nil, aliasError(err)
+		}
+	} else {
+		sub := newParser(resolved, p.fn, p.opts)
+		sub.resolving = append(append([]string(nil), p.resolving...), name)
+		if strings.TrimSpace(resolved) == "" {
+			return nil, aliasError(nil)
+		}
+		var err error
+		if n, err = sub.parse(); err != nil {
+			return nil, aliasError(err)
+		}
+	}
+	groups, err := n.dnf(false, "")
+	if err != nil {
+		return nil, aliasError(err)
+	}
+	return sourceNode(groups, src), nil
+}
+
+// sourceNode returns a node matching OR groups of AND comparators, whose
+// comparators take src as their original value.
+func sourceNode(groups [][]*constraint, src string) node {
+	var result node
+	for _, g := range groups {
+		atoms := make([]*constraint, 0, len(g))
+		for _, v := range g {
+			c := *v
+			c.original = src
+			atoms = append(atoms, &c)
+		}
+		var n node = &clauseNode{atoms: atoms}
+		if result != nil {
+			n = &orNode{left: result, right: n}
+		}
+		result = n
+	}
+	if result == nil {
+		// The alias matches no version.
+		return &notNode{operand: &clauseNode{}, src: src}
+	}
+	return result
+}
+
+type clauseWord struct {
+	text   string
+	offset int
+}
+
+// clauseWords splits a clause at the separator bytes in seps.
+func clauseWords(clause, seps string) []clauseWord {
+	var words []clauseWord
+	start := -1
+	for k := 0; k <= len(clause); k++ {
+		if k == len(clause) || strings.IndexByte(seps, clause[k]) >= 0 {
+			if start >= 0 {
+				words = append(words, clauseWord{text: clause[start:k], offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = k
+		}
+	}
+	return words
+}
@@ -0,0 +1,159 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAliases = map[string]string{
+	"latest": "2.4.1",
+	"stable": "2.3.0",
+	"lts":    "^1.22 || ~2.1",
+	"next":   "3.0.0-rc.1",
+	"legacy": "not lts",
+	"never":  ">=2 <1",
+	"loop":   "lts || loop",
+	"broken": ">=1.2 <",
+	"blank":  "",
+}
+
+func newAliasConstraint(c string) (*Constraints, error) {
+	return NewConstraint(c, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithAliases(testAliases))
+}
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"latest", "2.4.1"},
+		{"lts || >=2.3", ">=1.22.0 <2.0.0 || >=2.1.0 <2.2.0 || >=2.3.0"},
+		{"lts <2", ">=1.22.0 <2.0.0 || >=2.1.0 <2.0.0 <2.2.0"},
+		{"^stable", ">=2.3.0 <3.0.0"},
+		{">=stable <=latest", ">=2.3.0 <=2.4.1"},
+		{"!latest", "!=2.4.1"},
+		{"not lts", "<1.22.0 <2.1.0 || >=2.2.0 <1.22.0 || >=2.0.0 <2.1.0 || >=2.0.0 >=2.2.0"},
+		{"legacy", "<1.22.0 <2.1.0 || >=2.2.0 <1.22.0 || >=2.0.0 <2.1.0 || >=2.0.0 >=2.2.0"},
+		{">=stable || next", ">=2.3.0 || 3.0.0-rc.1"},
+		{"1.0 - 2.0 stable", ">=1.0.0 <=2.0.0 2.3.0"},
+		{"never", ">=2.0.0 <1.0.0"},
+		{"not (^1 || latest)", "<1.0.0 !=2.4.1 || >=2.0.0 !=2.4.1"},
+	}
+
+	for _, tc := range tests {
+		c, err := newAliasConstraint(tc.in)
+		assert.NoError(t, err, tc.in)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.expected, c.Canonical(), tc.in)
+	}
+}
+
+func TestAliasesCheck(t *testing.T) {
+	c, err := newAliasConstraint("lts || >=stable")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ver   string
+		valid bool
+	}{
+		{"1.22.3", true},
+		{"1.21.0", false},
+		{"2.0.5", false},
+		{"2.1.5", true},
+		{"2.2.0", false},
+		{"2.3.1", true},
+	}
+	for _, tc := range tests {
+		ok, err := c.CheckString(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, ok, tc.ver)
+	}
+
+	v, _ := NewSemverStr("2.2.0")
+	_, errs := c.Validate(v)
+	assert.EqualError(t, errs[0], "2.2.0 is greater than or equal to 2.0.0 (from lts)")
+	assert.EqualError(t, errs[2], "2.2.0 is less than 2.3.0 (from >=stable)")
+}
+
+func TestAliasesErrors(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int
+		token  string
+	}{
+		{"^1 || loop", 6, "loop"},
+		{"^1 broken", 3, "broken"},
+		{"blank", 0, "blank"},
+		{">=lts", 2, "lts"},
+	}
+
+	for _, tc := range tests {
+		_, err := newAliasConstraint(tc.in)
+		var pe *ParseError
+		assert.True(t, errors.As(err, &pe), tc.in)
+		assert.ErrorIs(t, err, ErrInvalidConstraint)
+		assert.Equal(t, tc.offset, pe.Offset, tc.in)
+		assert.Equal(t, tc.token, pe.Token, tc.in)
+	}
+
+	_, err := newAliasConstraint("^1 || loop")
+	assert.EqualError(t, err, `invalid constraint "^1 || loop": alias resolves to "lts || loop": `+
+		`alias refers to itself at offset 6: "loop"`)
+
+	_, err = newAliasConstraint(">=lts")
+	assert.EqualError(t, err, `invalid constraint ">=lts": alias resolves to "^1.22 || ~2.1": `+
+		`not a version at offset 2: "lts"`)
+
+	_, err = newAliasConstraint("^1 broken")
+	assert.EqualError(t, err, `invalid constraint "^1 broken": alias resolves to ">=1.2 <": `+
+		`missing version at offset 3: "broken"`)
+
+	// Names that are not aliases are parsed as versions.
+	_, err = newAliasConstraint("unknown")
+	assert.ErrorIs(t, err, ErrInvalidSemVer)
+}
+
+func TestAliasesDialects(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		in       string
+		expected string
+	}{
+		{DialectDefault, ">lts", ">1.2.0"},
+		{DialectDefault, "=lts", "1.2.0"},
+		{DialectNPM, ">lts", ">=1.3.0"},
+		{DialectNPM, "<=lts", "<1.3.0"},
+		{DialectNPM, "^lts", ">=1.2.0 <2.0.0"},
+		{DialectCargo, "=lts", ">=1.2.0 <1.3.0"},
+		{DialectCargo, ">=lts, <latest", ">=1.2.0 <2.4.1"},
+		{DialectCargo, ">=lts, <2", ">=1.2.0 <2.0.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.in, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		}, WithDialect(tc.dialect), WithAliases(map[string]string{"lts": "1.2", "latest": "2.4.1"}))
+		if assert.NoError(t, err, tc.in) {
+			assert.Equal(t, tc.expected, c.Canonical(), "%s %s", tc.dialect, tc.in)
+		}
+	}
+}
+
+func TestAliasResolver(t *testing.T) {
+	channels := map[string]string{"beta": "~2.5.0-beta"}
+	c, err := NewConstraint("channel-beta", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithAliasResolver(func(name string) (string, bool) {
+		v, ok := channels[name[len("channel-"):]]
+		return v, ok
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, ">=2.5.0-beta <2.6.0", c.Canonical())
+	assert.Equal(t, "channel-beta", c.String())
+}
@@ -0,0 +1,81 @@
+package vc
+
+import "strings"
+
+// Interval is a contiguous range of versions ordered by Compare. A nil
+// Lower or Upper means the interval is unbounded on that side.
+type Interval struct {
+	Lower          Comparable
+	LowerInclusive bool
+	Upper          Comparable
+	UpperInclusive bool
+}
+
+// Bounds returns the versions matched by the constraints as sorted,
+// disjoint intervals, e.g. "^1.2 !=1.5.0 || >=3" gives [1.2.0, 1.5.0),
+// (1.5.0, 2.0.0) and [3.0.0, +inf). Constraints that match no version give
+// no intervals. Under PrereleaseExclude, and under PrereleaseNPM without a
+// prerelease comparator, the constraints match the releases of the
+// intervals only. Bounds returns ErrPrereleasePolicy for constraints under
+// PrereleaseNPM with a prerelease comparator, whose prereleases are not
+// ranges, and ErrArbitraryEquality for PEP 440 "===" comparators.
+func (c *Constraints) Bounds() ([]Interval, error) {
+	if err := orderedSets(c); err != nil {
+		return nil, err
+	}
+	return c.set().intervals(), nil
+}
+
+// intervals converts the set to exported intervals.
+func (s intervalSet) intervals() []Interval {
+	result := make([]Interval, 0, len(s))
+	for _, i := range s {
+		result = append(result, Interval{
+			Lower:          i.lo.v,
+			LowerInclusive: i.lo.v != nil && i.lo.inclusive,
+			Upper:          i.hi.v,
+			UpperInclusive: i.hi.v != nil && i.hi.inclusive,
+		})
+	}
+	return result
+}
+
+// Contains tests if a version lies within the interval.
+func (i Interval) Contains(ver Comparable) bool {
+	return i.interval().contains(ver)
+}
+
+// String returns the interval in mathematical notation, e.g.
+// "[1.2.0, 2.0.0)" or "(-inf, 1.0.0]".
+func (i Interval) String() string {
+	var b strings.Builder
+	if i.Lower == nil {
+		b.WriteString("(-inf")
+	} else {
+		if i.LowerInclusive {
+			b.WriteByte('[')
+		} else {
+			b.WriteByte('(')
+		}
+		b.WriteString(formatVersion(i.Lower))
+	}
+	b.WriteString(", ")
+	if i.Upper == nil {
+		b.WriteString("+inf)")
+	} else {
+		b.WriteString(formatVersion(i.Upper))
+		if i.UpperInclusive {
+			b.WriteByte(']')
+		} else {
+			b.WriteByte(')')
+		}
+	}
+	return b.String()
+}
+
+func (i Interval) interval() interval {
+	return interval{
+		lo: bound{v: i.Lower, inclusive: i.LowerInclusive},
+		hi: bound{v: i.Upper, inclusive: i.UpperInclusive},
+	}
+}
@@ -0,0 +1,92 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintsBounds(t *testing.T) {
+	tests := []struct {
+		con      string
+		expected []string
+	}{
+		{"^1.2.3", []string{"[1.2.3, 2.0.0)"}},
+		{"~1.2 || ^3", []string{"[1.2.0, 1.3.0)", "[3.0.0, 4.0.0)"}},
+		{"1.2.x", []string{"[1.2.0, 1.3.0)"}},
+		{"*", []string{"[0.0.0, +inf)"}},
+		{"<=1.5.0", []string{"(-inf, 1.5.0]"}},
+		{">1.0.0", []string{"(1.0.0, +inf)"}},
+		{"=1.4.2", []string{"[1.4.2, 1.4.2]"}},
+		{"^1 !=1.5.0", []string{"[1.0.0, 1.5.0)", "(1.5.0, 2.0.0)"}},
+		{"^1.2 || ~1.4 || ^2", []string{"[1.2.0, 3.0.0)"}},
+		{"1.0.0 - 2.0.0-rc.1", []string{"[1.0.0, 2.0.0-rc.1]"}},
+		{"^1 ^3", []string{}},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		bounds, err := c.Bounds()
+		assert.NoError(t, err)
+		got := make([]string, 0)
+		for _, i := range bounds {
+			got = append(got, i.String())
+		}
+		assert.Equal(t, tc.expected, got, tc.con)
+	}
+}
+
+func TestIntervalFields(t *testing.T) {
+	c := mustSemverConstraint(t, "<1.0.0 || ^2")
+	bounds, err := c.Bounds()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(bounds))
+
+	assert.Nil(t, bounds[0].Lower)
+	assert.False(t, bounds[0].LowerInclusive)
+	assert.Equal(t, "1.0.0", formatVersion(bounds[0].Upper))
+	assert.False(t, bounds[0].UpperInclusive)
+
+	assert.Equal(t, "2.0.0", formatVersion(bounds[1].Lower))
+	assert.True(t, bounds[1].LowerInclusive)
+	assert.Equal(t, "3.0.0", formatVersion(bounds[1].Upper))
+	assert.False(t, bounds[1].UpperInclusive)
+
+	for _, tc := range []struct {
+		ver      string
+		expected bool
+	}{
+		{"0.9.0", true},
+		{"1.0.0", false},
+		{"2.0.0", true},
+		{"2.9.9", true},
+		{"3.0.0", false},
+	} {
+		v, _ := NewSemverStr(tc.ver)
+		assert.Equal(t, tc.expected, bounds[0].Contains(v) || bounds[1].Contains(v), tc.ver)
+		assert.Equal(t, c.Check(v), tc.expected, tc.ver)
+	}
+}
+
+func TestConstraintsBoundsPolicy(t *testing.T) {
+	newfn := func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}
+	c, err := NewConstraint("^1.2 || ~3.1", newfn, WithDialect(DialectNPM))
+	assert.NoError(t, err)
+	bounds, err := c.Bounds()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(bounds))
+	assert.Equal(t, "[1.2.0, 2.0.0)", bounds[0].String())
+
+	spec, err := NewPEP440Specifier(">=1.0, <2")
+	assert.NoError(t, err)
+	bounds, err = spec.Bounds()
+	assert.NoError(t, err)
+	assert.Equal(t, "[1.0, 2.dev0)", bounds[0].String())
+
+	c, err = NewConstraint("^1.2.3-beta", newfn, WithDialect(DialectNPM))
+	assert.NoError(t, err)
+	_, err = c.Bounds()
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+}
@@ -0,0 +1,168 @@
+package vc
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// Version schemes registered by NewConstraintCache.
+const (
+	SchemeSemver = "semver"
+	SchemeCalVer = "calver"
+)
+
+// DefaultCacheSize is the capacity of a ConstraintCache created with a
+// size of zero or less.
+const DefaultCacheSize = 256
+
+// ConstraintCache is a bounded LRU cache of parsed constraints, keyed by the
+// version scheme and the expression. It is safe for concurrent use.
+//
+// Cached Constraints are shared between callers. Constraints have no
+// mutating methods, so they can be used concurrently.
+type ConstraintCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	items   map[cacheKey]*list.Element
+	schemes map[string]cacheScheme
+	hits    uint64
+	misses  uint64
+}
+
+type cacheKey struct {
+	scheme, expr string
+}
+
+type cacheEntry struct {
+	key cacheKey
+	c   *Constraints
+}
+
+type cacheScheme struct {
+	fn   New
+	opts []Option
+	// gen counts the registrations of the scheme, so a parse made with a
+	// replaced parser is not cached.
+	gen uint64
+}
+
+// CacheStats reports the use of a ConstraintCache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Len is the number of cached constraints.
+	Len int
+}
+
+// NewConstraintCache creates a cache holding up to size constraints, with
+// SchemeSemver and SchemeCalVer registered.
+func NewConstraintCache(size int) *ConstraintCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	c := &ConstraintCache{
+		size:    size,
+		ll:      list.New(),
+		items:   make(map[cacheKey]*list.Element),
+		schemes: make(map[string]cacheScheme),
+	}
+	c.Register(SchemeSemver, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	c.Register(SchemeCalVer, func(s string) (Comparable, error) {
+		return NewCalVerStr(s)
+	})
+	return c
+}
+
+// Register adds a version scheme, or replaces the parser and options of a
+// registered one. Replacing a scheme drops its cached constraints.
+func (c *ConstraintCache) Register(scheme string, fn New, opts ...Option) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, ok := c.schemes[scheme]
+	if ok {
+		for e := c.ll.Front(); e != nil; {
+			next := e.Next()
+			if entry := e.Value.(*cacheEntry); entry.key.scheme == scheme {
+				c.ll.Remove(e)
+				delete(c.items, entry.key)
+			}
+			e = next
+		}
+	}
+	c.schemes[scheme] = cacheScheme{fn: fn, opts: opts, gen: prev.gen + 1}
+}
+
+// Get returns the semver constraints of an expression, parsing it on a
+// cache miss.
+func (c *ConstraintCache) Get(expr string) (*Constraints, error) {
+	return c.GetScheme(SchemeSemver, expr)
+}
+
+// GetScheme returns the constraints of an expression in a registered
+// version scheme, parsing it on a cache miss. Expressions that fail to parse
+// are not cached.
+func (c *ConstraintCache) GetScheme(scheme, expr string) (*Constraints, error) {
+	key := cacheKey{scheme: scheme, expr: expr}
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.hits++
+		c.ll.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).c, nil
+	}
+	c.misses++
+	s, ok := c.schemes[scheme]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownScheme, scheme)
+	}
+
+	// Parse without holding the lock, so a slow expression does not block
+	// the other callers.
+	con, err := NewConstraint(expr, s.fn, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schemes[scheme].gen != s.gen {
+		// The scheme was registered again meanwhile and its cached
+		// constraints dropped, do not cache a parse made with the old one.
+		return con, nil
+	}
+	if e, ok := c.items[key]; ok {
+		// Another caller parsed the same expression meanwhile.
+		c.ll.MoveToFront(e)
+		return e.Value.(*cacheEntry).c, nil
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, c: con})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+	return con, nil
+}
+
+// Stats returns the hit and miss counters and the number of cached
+// constraints.
+func (c *ConstraintCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Len: c.ll.Len()}
+}
+
+// Purge removes every cached constraint. The counters are kept.
+func (c *ConstraintCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[cacheKey]*list.Element)
+}
@@ -0,0 +1,118 @@
+package vc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintCache(t *testing.T) {
+	cache := NewConstraintCache(2)
+
+	a, err := cache.Get(">=2023.1")
+	assert.NoError(t, err)
+	b, err := cache.Get(">=2023.1")
+	assert.NoError(t, err)
+	assert.Same(t, a, b)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Len: 1}, cache.Stats())
+
+	// The scheme is part of the key.
+	cv, err := cache.GetScheme(SchemeCalVer, ">=2023.1")
+	assert.NoError(t, err)
+	assert.NotSame(t, a, cv)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Len: 2}, cache.Stats())
+
+	// The semver >=2023.1 was used least recently and is evicted.
+	_, err = cache.Get("~2.0")
+	assert.NoError(t, err)
+	c, err := cache.Get(">=2023.1")
+	assert.NoError(t, err)
+	assert.NotSame(t, a, c)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 4, Len: 2}, cache.Stats())
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Stats().Len)
+}
+
+func TestConstraintCacheErrors(t *testing.T) {
+	cache := NewConstraintCache(0)
+
+	_, err := cache.Get(">=1.2 <")
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+	assert.Equal(t, CacheStats{Misses: 1}, cache.Stats())
+
+	_, err = cache.GetScheme("pep440", ">=1.2")
+	assert.ErrorIs(t, err, ErrUnknownScheme)
+	assert.EqualError(t, err, `unknown version scheme: "pep440"`)
+}
+
+func TestConstraintCacheRegister(t *testing.T) {
+	cache := NewConstraintCache(8)
+	a, err := cache.Get(">=1.0.0")
+	assert.NoError(t, err)
+	ok, _ := a.CheckString("2.0.0-alpha")
+	assert.True(t, ok)
+
+	cache.Register(SchemeSemver, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	b, err := cache.Get(">=1.0.0")
+	assert.NoError(t, err)
+	ok, _ = b.CheckString("2.0.0-alpha")
+	assert.False(t, ok)
+}
+
+func TestConstraintCacheConcurrent(t *testing.T) {
+	cache := NewConstraintCache(4)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c, err := cache.Get(fmt.Sprintf("^%d", (i+j)%6))
+				assert.NoError(t, err)
+				assert.True(t, c.Check(NewSemver(uint64((i+j)%6), 1, 0, "", "")))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(1600), stats.Hits+stats.Misses)
+	assert.Equal(t, 4, stats.Len)
+}
+
+func TestConstraintCacheConcurrentRegister(t *testing.T) {
+	cache := NewConstraintCache(8)
+	fn := func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := cache.Get("1.2")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			cache.Register(SchemeSemver, fn, WithDialect(Dialect(j%2)))
+		}
+	}()
+	wg.Wait()
+
+	// The last registration uses the npm dialect, where 1.2 is 1.2.x.
+	c, err := cache.Get("1.2")
+	assert.NoError(t, err)
+	ok, _ := c.CheckString("1.2.5")
+	assert.True(t, ok)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -30,10 +29,13 @@ type CalVer struct {
 // NewCalVerStr parses a given version and returns an instance of CalVer or
 // an error if unable to parse the version. If the version is SemVer-ish it
 // attempts to convert it to CalVer.
+//
+// Parse failures are reported as a *ParseError wrapping ErrInvalidCalVer,
+// ErrSegmentStartsZero or ErrInvalidPrerelease.
 func NewCalVerStr(ver string) (*CalVer, error) {
 	m := calVersionRegex.FindStringSubmatch(ver)
 	if m == nil {
-		return nil, ErrInvalidCalVer
+		return nil, calVerSyntaxError(ver)
 	}
 
 	sv := &CalVer{
@@ -42,23 +44,23 @@ func NewCalVerStr(ver string) (*CalVer, error) {
 	}
 
 	var err error
-	sv.major, err = strconv.ParseUint(m[1], 10, 64)
+	sv.major, err = parseSegment(ErrInvalidCalVer, ver, m[1], 0)
 	if err != nil {
-		return nil, fmt.Errorf("parsing version segment: %s", err)
+		return nil, err
 	}
 	if m[2] != "" {
-		sv.minor, err = strconv.ParseUint(strings.TrimPrefix(m[2], "."), 10, 64)
+		sv.minor, err = parseSegment(ErrInvalidCalVer, ver, strings.TrimPrefix(m[2], "."), len(m[1])+1)
 		if err != nil {
-			return nil, fmt.Errorf("parsing version segment: %s", err)
+			return nil, err
 		}
 	} else {
 		sv.minor = 0
 	}
 
 	if m[3] != "" {
-		sv.patch, err = strconv.ParseUint(strings.TrimPrefix(m[3], "."), 10, 64)
+		sv.patch, err = parseSegment(ErrInvalidCalVer, ver, strings.TrimPrefix(m[3], "."), len(m[1])+len(m[2])+1)
 		if err != nil {
-			return nil, fmt.Errorf("parsing version segment: %s", err)
+			return nil, err
 		}
 	} else {
 		sv.patch = 0
@@ -68,12 +70,38 @@ func NewCalVerStr(ver string) (*CalVer, error) {
 	// valid.
 	if sv.pre != "" {
 		if err = validatePrerelease(sv.pre); err != nil {
-			return nil, err
+			return nil, invalidPart(err, ver, sv.pre, len(ver)-len(sv.pre))
 		}
 	}
 	return sv, nil
 }
 
+// calVerSyntaxError locates the part of ver that is not a calendar version.
+func calVerSyntaxError(ver string) *ParseError {
+	offset, reason := locateSyntaxError(ver, false, false)
+	if offset >= 0 {
+		return newSyntaxError(ErrInvalidCalVer, ver, offset, reason)
+	}
+	// The grammar matches, so one of the segments has the wrong width.
+	release := ver
+	if i := strings.IndexByte(ver, '-'); i >= 0 {
+		release = ver[:i]
+	}
+	offset = 0
+	for k, seg := range strings.Split(release, ".") {
+		if k == 0 && len(seg) != 2 && len(seg) != 4 {
+			return &ParseError{Input: ver, Offset: offset, Token: seg,
+				Reason: "year must have 2 or 4 digits", Err: ErrInvalidCalVer}
+		}
+		if k > 0 && len(seg) > 2 {
+			return &ParseError{Input: ver, Offset: offset, Token: seg,
+				Reason: "month and day must have 1 or 2 digits", Err: ErrInvalidCalVer}
+		}
+		offset += len(seg) + 1
+	}
+	return newSyntaxError(ErrInvalidCalVer, ver, 0, "not a calendar version")
+}
+
 // NewCalVer creates a new instance of CalVer with each of the parts passed in as
 // arguments instead of parsing a version string.
 func NewCalVer(major, minor, patch uint64, pre string) *CalVer {
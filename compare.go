@@ -1,6 +1,7 @@
 package vc
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -25,6 +26,17 @@ type Comparable interface {
 	IncPatch() Comparable
 }
 
+// Ordered is implemented by versions whose order cannot be derived from
+// their major, minor, patch and prerelease parts alone, e.g. PEP440 with its
+// epochs, post and local releases. Compare defers to CompareTo when both
+// versions implement Ordered.
+type Ordered interface {
+	Comparable
+	// CompareTo returns -1, 0, or 1 if the version is smaller, equal, or
+	// larger than the other version.
+	CompareTo(other Comparable) int
+}
+
 // Lt tests if one version is less than another one.
 func Lt(v1, v2 Comparable) bool {
 	return Compare(v1, v2) < 0
@@ -50,6 +62,17 @@ func Eq(v1, v2 Comparable) bool {
 // If you want to work with ranges using typical range syntax that
 // skip prerelease if the range is not looking for them use constraints.
 func Compare(v1, v2 Comparable) int {
+	if o1, ok := v1.(Ordered); ok {
+		if _, ok := v2.(Ordered); ok {
+			return o1.CompareTo(v2)
+		}
+	}
+	return compareParts(v1, v2)
+}
+
+// compareParts compares two versions by their major, minor, patch and
+// prerelease parts.
+func compareParts(v1, v2 Comparable) int {
 	// Compare the major, minor, and patch version for differences. If a
 	// difference is found return the comparison.
 	if d := compareSegment(v1.Major(), v2.Major()); d != 0 {
@@ -222,3 +245,116 @@ func containsOnly(s string, comp string) bool {
 		return !strings.ContainsRune(comp, r)
 	}) == -1
 }
+
+// locateSyntaxError finds the first part of ver that does not match the
+// grammar [v]MAJOR[.MINOR[.PATCH]][-PRERELEASE][+METADATA]. It returns the
+// offset of that part and the reason it was rejected, or -1 if ver matches
+// the grammar. The v prefix and metadata are only accepted when allowed.
+func locateSyntaxError(ver string, vPrefix, metadata bool) (int, string) {
+	i := 0
+	if vPrefix && strings.HasPrefix(ver, "v") {
+		i++
+	}
+	for seg := 0; seg < 3; seg++ {
+		if seg > 0 {
+			if i >= len(ver) || ver[i] != '.' {
+				break
+			}
+			i++
+		}
+		j := i
+		for j < len(ver) && strings.IndexByte(allowedNum, ver[j]) >= 0 {
+			j++
+		}
+		if j == i {
+			return i, "expected a number"
+		}
+		i = j
+	}
+	if i < len(ver) && ver[i] == '-' {
+		end, ok := scanIdentifiers(ver, i+1)
+		if !ok {
+			return end, "expected a prerelease identifier"
+		}
+		i = end
+	}
+	if metadata && i < len(ver) && ver[i] == '+' {
+		end, ok := scanIdentifiers(ver, i+1)
+		if !ok {
+			return end, "expected a metadata identifier"
+		}
+		i = end
+	}
+	if i < len(ver) {
+		return i, "unexpected character"
+	}
+	return -1, ""
+}
+
+// scanIdentifiers scans dot separated, non-empty identifiers made of
+// [0-9A-Za-z-] starting at i. It returns the offset after the last
+// identifier, or the offset of an empty identifier and false.
+func scanIdentifiers(s string, i int) (int, bool) {
+	for {
+		j := i
+		for j < len(s) && strings.IndexByte(allowedChars, s[j]) >= 0 {
+			j++
+		}
+		if j == i {
+			return i, false
+		}
+		if j >= len(s) || s[j] != '.' {
+			return j, true
+		}
+		i = j + 1
+	}
+}
+
+// newSyntaxError creates a ParseError for ver, which the version grammar
+// rejects at offset.
+func newSyntaxError(err error, ver string, offset int, reason string) *ParseError {
+	return &ParseError{Input: ver, Offset: offset, Token: ver[offset:], Reason: reason, Err: err}
+}
+
+// parseSegment parses a numeric version segment found at offset in ver.
+func parseSegment(sentinel error, ver, seg string, offset int) (uint64, error) {
+	n, err := strconv.ParseUint(seg, 10, 64)
+	if err != nil {
+		return 0, &ParseError{
+			Input:  ver,
+			Offset: offset,
+			Token:  seg,
+			Reason: fmt.Sprintf("parsing version segment: %s", err),
+			Err:    sentinel,
+		}
+	}
+	return n, nil
+}
+
+// invalidPart creates a ParseError for the prerelease or metadata part found
+// at offset in ver, that failed validation with err. The error reports the
+// first identifier of the part that fails, e.g. 01 in alpha.01.beta.
+func invalidPart(err error, ver, part string, offset int) *ParseError {
+	validate := validatePrerelease
+	if err == ErrInvalidMetadata {
+		validate = validateMetadata
+	}
+	start := 0
+	for _, id := range strings.Split(part, ".") {
+		if validate(id) != nil {
+			offset, part = offset+start, id
+			break
+		}
+		start += len(id) + 1
+	}
+	reason := "invalid identifier"
+	switch err {
+	case ErrSegmentStartsZero:
+		reason = "numeric identifier starts with 0"
+	case ErrInvalidPrerelease:
+		reason = "invalid prerelease identifier"
+	case ErrInvalidMetadata:
+		reason = "invalid metadata identifier"
+	}
+	return &ParseError{Input: ver, Offset: offset, Token: part, Reason: reason, Err: err}
+}
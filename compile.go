@@ -0,0 +1,98 @@
+package vc
+
+import "sort"
+
+// Matcher checks versions against compiled constraints. It holds the
+// matched versions as sorted, disjoint intervals and finds the interval of
+// a version with a binary search, instead of evaluating every comparator.
+// A Matcher is immutable and safe for concurrent use.
+type Matcher struct {
+	set    intervalSet
+	policy PrereleasePolicy
+	// Under PrereleaseNPM, the versions a prerelease of each
+	// major.minor.patch may match: the union of the groups with a
+	// comparator on a prerelease of that release.
+	prereleases map[release]intervalSet
+	// The constraints themselves when a comparator compares versions as
+	// written, which confirm the versions found in set.
+	arbitrary *Constraints
+}
+
+type release struct {
+	major, minor, patch uint64
+}
+
+func releaseOf(v Comparable) release {
+	return release{major: v.Major(), minor: v.Minor(), patch: v.Patch()}
+}
+
+// Compile turns the constraints into a Matcher for checking many versions.
+// The Matcher accepts the same versions as Check, including under the
+// prerelease policy of the constraints.
+func (c *Constraints) Compile() *Matcher {
+	m := &Matcher{set: c.set(), policy: c.policy}
+	if c.arbitrary() {
+		m.arbitrary = c
+	}
+	if c.policy != PrereleaseNPM {
+		return m
+	}
+	m.prereleases = make(map[release]intervalSet)
+	for _, g := range c.constraints {
+		var set intervalSet
+		seen := make(map[release]bool)
+		for _, v := range g {
+			if v.com.Prerelease() == "" || seen[releaseOf(v.com)] {
+				continue
+			}
+			if set == nil {
+				set = groupSet(g)
+			}
+			r := releaseOf(v.com)
+			seen[r] = true
+			m.prereleases[r] = m.prereleases[r].union(set)
+		}
+	}
+	return m
+}
+
+// Check tests if a version satisfies the compiled constraints.
+func (m *Matcher) Check(ver Comparable) bool {
+	if m.arbitrary != nil {
+		return m.set.search(ver) && m.arbitrary.Check(ver)
+	}
+	if m.policy != PrereleaseIncludeAll && ver.Prerelease() != "" {
+		return m.prereleases[releaseOf(ver)].search(ver)
+	}
+	return m.set.search(ver)
+}
+
+// CheckAll checks each version, the result at index i reports whether vs[i]
+// satisfies the compiled constraints.
+func (m *Matcher) CheckAll(vs []Comparable) []bool {
+	result := make([]bool, len(vs))
+	for k, v := range vs {
+		result[k] = m.Check(v)
+	}
+	return result
+}
+
+// CheckAll compiles the constraints and checks each version, see
+// Matcher.CheckAll. Keep the Matcher of Compile to check several batches.
+func (c *Constraints) CheckAll(vs []Comparable) []bool {
+	return c.Compile().CheckAll(vs)
+}
+
+// search tests if the set contains a version with a binary search for the
+// first interval whose upper bound is not below it.
+func (s intervalSet) search(ver Comparable) bool {
+	k := sort.Search(len(s), func(i int) bool {
+		hi := s[i].hi
+		if hi.v == nil {
+			return true
+		}
+		d := Compare(ver, hi.v)
+		return d < 0 || (d == 0 && hi.inclusive)
+	})
+	return k < len(s) && s[k].contains(ver)
+}
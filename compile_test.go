@@ -0,0 +1,118 @@
+package vc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var compileVersions = []string{
+	"0.0.0", "0.9.9", "1.0.0-alpha", "1.0.0", "1.2.0", "1.2.3-beta.1", "1.2.3-beta.4",
+	"1.2.3", "1.4.5", "1.4.6", "1.5.0-rc.1", "1.9.9", "2.0.0-alpha", "2.0.0", "2.5.1",
+	"3.0.0", "3.1.0", "4.0.0-rc.1", "10.0.0",
+}
+
+func TestMatcherCheck(t *testing.T) {
+	cons := []string{
+		"^1.2",
+		"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)",
+		"<1.0.0 || =2.0.0 || >3",
+		">1.2.3-beta.2 <2 || ^3.0.0-rc.1",
+		"^1 !=1.2.3 !=1.4.6",
+		"*",
+		"^1 ^3",
+	}
+
+	vs := make([]Comparable, 0, len(compileVersions))
+	for _, s := range compileVersions {
+		v, err := NewSemverStr(s)
+		assert.NoError(t, err)
+		vs = append(vs, v)
+	}
+
+	for _, con := range cons {
+		for _, policy := range []PrereleasePolicy{PrereleaseIncludeAll, PrereleaseNPM} {
+			c, err := NewConstraint(con, func(s string) (Comparable, error) {
+				return NewSemverStr(s)
+			}, WithPrereleasePolicy(policy))
+			assert.NoError(t, err)
+
+			got := c.Compile().CheckAll(vs)
+			assert.Equal(t, got, c.CheckAll(vs))
+			for k, v := range vs {
+				assert.Equal(t, c.Check(v), got[k], "%s %s (policy %d)", con, compileVersions[k], policy)
+			}
+		}
+	}
+}
+
+func TestMatcherPrerelease(t *testing.T) {
+	c, err := NewConstraint(">=1.2.3-beta.2 <2 || ~3.0.0-rc.1", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+	m := c.Compile()
+
+	tests := []struct {
+		ver   string
+		valid bool
+	}{
+		{"1.2.3-beta.4", true},
+		{"1.2.3-beta.1", false},
+		{"1.2.4-beta", false},
+		{"1.5.0", true},
+		{"3.0.0-rc.2", true},
+		{"3.0.1-rc.2", false},
+		{"3.0.5", true},
+	}
+	for _, tc := range tests {
+		v, _ := NewSemverStr(tc.ver)
+		assert.Equal(t, tc.valid, m.Check(v), tc.ver)
+	}
+}
+
+func benchmarkVersions(b *testing.B) []Comparable {
+	vs := make([]Comparable, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		v, err := NewSemverStr(fmt.Sprintf("%d.%d.%d", i%5, i%13, i%7))
+		if err != nil {
+			b.Fatal(err)
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+const benchmarkConstraint = "^1.2 !=1.4.5 || ~2.3 || >=3.1 <3.5 || 4.x !=4.2.1"
+
+func BenchmarkConstraintsCheck(b *testing.B) {
+	c, err := NewConstraint(benchmarkConstraint, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	vs := benchmarkVersions(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range vs {
+			c.Check(v)
+		}
+	}
+}
+
+func BenchmarkMatcherCheckAll(b *testing.B) {
+	c, err := NewConstraint(benchmarkConstraint, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	m := c.Compile()
+	vs := benchmarkVersions(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.CheckAll(vs)
+	}
+}
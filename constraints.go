@@ -1,9 +1,12 @@
 package vc
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 )
 
 const (
@@ -12,6 +15,7 @@ const (
 	OperatorLte   = "<="
 	OperatorLt    = "<"
 	OperatorEq    = "="
+	OperatorNe    = "!="
 	OperatorRange = " - "
 	OperatorCaret = "^"
 	OperatorTilde = "~"
@@ -33,29 +37,86 @@ type operation func(v Comparable, c *constraint) bool
 type Constraints struct {
 	constraints [][]*constraint
 	newfn       New
+	// The expression the constraints were parsed from
+	original string
+	policy   PrereleasePolicy
+	// The options the constraints were parsed with
+	opts []Option
+	// The syntax of the version scheme the constraints were parsed from,
+	// e.g. "PEP 440 specifier", empty for the syntax of NewConstraint
+	syntax string
 }
 
 // New a function to generate a Comparable instance.
 type New func(string) (Comparable, error)
 
 // NewConstraint returns a Constraints instance that a Comparable instance can
-// be checked against. If there is a parse error it will be returned.
-func NewConstraint(c string, fn New) (*Constraints, error) {
-	if strings.TrimSpace(c) == "" {
-		return nil, ErrInvalidConstraint
+// be checked against. If there is a parse error it will be returned as a
+// *ParseError locating the offending part of the expression.
+//
+// Besides the flat syntax, e.g. ">=1.2 <2 || >=3", expressions may be nested
+// with parentheses and combined with "&&"/"and", "||"/"or" and "!"/"not",
+// e.g. "(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)".
+//
+// Options such as WithPrereleasePolicy change how the constraints are
+// checked.
+//
+// NewConstraint accepts any Comparable, use ParseConstraint or its Semver
+// and CalVer variants to only accept versions of a single type.
+func NewConstraint(c string, fn New, opts ...Option) (*Constraints, error) {
+	con, err := ParseConstraint(c, fn, opts...)
+	if err != nil {
+		return nil, err
 	}
-	groups := strings.Split(c, "||")
-	gcs := make([][]*constraint, len(groups))
+	return con.Constraints(), nil
+}
 
-	for k, v := range groups {
-		var result []*constraint
-		err := parseConstraintGroup(v, fn, &result)
-		if err != nil {
-			return nil, err
+// String returns the expression the constraints were parsed from, exactly as
+// it was given. Constraints that were not parsed from an expression are
+// rendered in canonical form.
+func (c *Constraints) String() string {
+	if c.original != "" {
+		return c.original
+	}
+	return c.Canonical()
+}
+
+// Canonical returns the normalized form of the constraints. Caret, tilde,
+// wildcard and hyphen ranges are expanded into the comparators they stand
+// for, "!" is written as "!=", "=" is omitted, duplicated comparators are
+// dropped and the comparators of each group are ordered lower bounds first,
+// so two spellings of the same policy print identically:
+//
+//	^1.2.3 || 2.1.x  -->  >=1.2.3 <2.0.0 || >=2.1.0 <2.2.0
+//	<2 >=1.2.3       -->  >=1.2.3 <2.0.0
+//
+// A group without comparators is written as "*". Constraints without any
+// group, such as the intersection of ^1 and ^2, match no version and are
+// written as ">=0.0.0 <0.0.0", which parses back to constraints matching
+// nothing.
+func (c *Constraints) Canonical() string {
+	if len(c.constraints) == 0 {
+		return OperatorGte + VersionMinimum + " " + OperatorLt + VersionMinimum
+	}
+	groups := make([]string, 0, len(c.constraints))
+	for _, g := range c.constraints {
+		groups = append(groups, canonicalGroup(g))
+	}
+	return strings.Join(groups, " || ")
+}
+
+// Groups returns the parsed constraints as a list of OR groups, each of which
+// is a list of comparators that must all be satisfied. Caret, tilde, wildcard
+// and hyphen ranges appear expanded into the comparators they stand for.
+func (c *Constraints) Groups() [][]Comparator {
+	groups := make([][]Comparator, len(c.constraints))
+	for k, g := range c.constraints {
+		groups[k] = make([]Comparator, len(g))
+		for k2, v := range g {
+			groups[k][k2] = Comparator{c: v}
 		}
-		gcs[k] = result
 	}
-	return &Constraints{constraints: gcs, newfn: fn}, nil
+	return groups
 }
 
 func (c *Constraints) Check(ver Comparable) bool {
@@ -70,6 +131,38 @@ func (c *Constraints) CheckString(ver string) (bool, error) {
 	return c.check(com), nil
 }
 
+// Validate checks a version against the constraints and explains a failure.
+// If the version does not satisfy any group, the returned errors list the
+// first comparator that failed in each group, e.g.
+// "1.4.0 is less than 1.5.0 (from ^1.5)".
+func (c *Constraints) Validate(ver Comparable) (bool, []error) {
+	var errs []error
+	for _, v := range c.constraints {
+		var failed error
+		for _, v2 := range v {
+			if !operatorsMap[v2.operator](ver, v2) {
+				failed = v2.failure(ver)
+				break
+			}
+		}
+		if failed == nil && !c.policy.admitsPrerelease(ver, v) {
+			if c.policy == PrereleaseExclude {
+				failed = fmt.Errorf("%s is a prerelease and prereleases are excluded (from %s)",
+					formatVersion(ver), groupSource(v))
+			} else {
+				failed = fmt.Errorf("%s is a prerelease and no comparator of %s is a prerelease of %s",
+					formatVersion(ver), groupSource(v), ver.Version())
+			}
+		}
+		if failed == nil {
+			return true, nil
+		}
+		errs = append(errs, failed)
+	}
+
+	return false, errs
+}
+
 func (c *Constraints) check(ver Comparable) bool {
 	for _, v := range c.constraints {
 		joy := true
@@ -79,7 +172,7 @@ func (c *Constraints) check(ver Comparable) bool {
 				break
 			}
 		}
-		if joy {
+		if joy && c.policy.admitsPrerelease(ver, v) {
 			return true
 		}
 	}
@@ -87,41 +180,123 @@ func (c *Constraints) check(ver Comparable) bool {
 	return false
 }
 
-func parseConstraintGroup(group string, fn New, result *[]*constraint) error {
+func canonicalGroup(group []*constraint) string {
+	if len(group) == 0 {
+		return VersionAll
+	}
+	sorted := canonicalOrder(group)
+	atoms := make([]string, 0, len(sorted))
+	for _, v := range sorted {
+		atoms = append(atoms, v.canonical())
+	}
+	return strings.Join(atoms, " ")
+}
+
+// canonicalOrder returns the comparators of a group without duplicates,
+// lower bounds first.
+func canonicalOrder(group []*constraint) []*constraint {
+	sorted := make([]*constraint, len(group))
+	copy(sorted, group)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := operatorRank(sorted[i].operator), operatorRank(sorted[j].operator)
+		if ri != rj {
+			return ri < rj
+		}
+		return Compare(sorted[i].com, sorted[j].com) < 0
+	})
+
+	result := make([]*constraint, 0, len(sorted))
+	for k, v := range sorted {
+		if k > 0 && sorted[k-1].canonicalOperator() == v.canonicalOperator() &&
+			Compare(sorted[k-1].com, v.com) == 0 {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// operatorRank orders comparators in canonical form: lower bounds, upper
+// bounds, exact matches and then exclusions.
+func operatorRank(op string) int {
+	switch op {
+	case OperatorGte, OperatorGt:
+		return 0
+	case OperatorLt, OperatorLte:
+		return 1
+	case OperatorEq, pep440Arbitrary:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// parseGroup parses the flat syntax of a dialect.
+func parseGroup(group string, fn New, d Dialect, result *[]*constraint) error {
+	input := group
 	group = strings.TrimSpace(group)
+	start := strings.Index(input, group)
 	if strings.Contains(group, OperatorRange) {
 		gs := strings.Split(group, OperatorRange)
 		// Contains more than one " - "
 		if len(gs) > 2 {
-			return ErrInvalidConstraint
+			return &ParseError{
+				Input:  input,
+				Offset: start + len(gs[0]) + len(OperatorRange) + len(gs[1]),
+				Token:  strings.TrimSpace(OperatorRange),
+				Reason: "hyphen range has more than two ends",
+				Err:    ErrInvalidConstraint,
+			}
 		}
 		if len(gs) > 1 {
-			err := parseConstraintGroup(OperatorGte+gs[0], fn, result)
+			n := len(*result)
+			err := parseGroup(OperatorGte+gs[0], fn, d, result)
 			if err != nil {
-				return err
+				return relocate(err, input, start-len(OperatorGte), gs[0])
 			}
-			err = parseConstraintGroup(OperatorLte+gs[1], fn, result)
+			err = parseGroup(OperatorLte+gs[1], fn, d, result)
 			if err != nil {
-				return err
+				upper := start + len(gs[0]) + len(OperatorRange)
+				return relocate(err, input, upper-len(OperatorLte), gs[1])
+			}
+			hi := strings.Fields(gs[1])[0]
+			for _, v := range (*result)[n:] {
+				if v.source() == OperatorGte+gs[0] || v.source() == OperatorLte+hi {
+					v.term = gs[0] + OperatorRange + hi
+				}
 			}
 		}
 	} else if strings.Contains(group, " ") {
 		gs := strings.Split(group, " ")
+		offset := start
 		for _, gv := range gs {
 			if gv != "" {
-				err := parseConstraintGroup(gv, fn, result)
+				err := parseGroup(gv, fn, d, result)
 				if err != nil {
-					return err
+					return relocate(err, input, offset, gv)
 				}
 			}
+			offset += len(gv) + 1
 		}
 	} else {
 		if group == VersionAll || strings.HasPrefix(group, VersionAll) {
 			group = VersionAllAlias
 		}
-		cons, err := parseConstraint(group, fn)
+		written, shift := group, 0
+		switch d {
+		case DialectNPM:
+			group = padPartial(group)
+		case DialectCargo:
+			group, shift = cargoRequirement(group)
+		}
+		cons, err := parseConstraint(group, fn, d)
 		if err != nil {
-			return err
+			return relocate(err, input, start-shift, group)
+		}
+		for _, v := range cons {
+			if written != group {
+				v.term = written
+			}
 		}
 		*result = append(*result, cons...)
 	}
@@ -133,13 +308,14 @@ var findConstraintRegex *regexp.Regexp
 
 func init() {
 	operatorsMap = map[string]operation{
-		"=":  constraintEqual,
-		"!":  constraintNotEqual,
-		"!=": constraintNotEqual,
-		">":  constraintGreaterThan,
-		"<":  constraintLessThan,
-		">=": constraintGreaterThanEqual,
-		"<=": constraintLessThanEqual,
+		"=":   constraintEqual,
+		"!":   constraintNotEqual,
+		"!=":  constraintNotEqual,
+		">":   constraintGreaterThan,
+		"<":   constraintLessThan,
+		">=":  constraintGreaterThanEqual,
+		"<=":  constraintLessThanEqual,
+		"===": constraintArbitraryEqual,
 	}
 
 	ops := `\^|>=|<=|!=|!|>|<|~|=`
@@ -157,15 +333,148 @@ type constraint struct {
 	// The original operator for the constraint
 	operator string
 	com      Comparable
+	// The term as written when it differs from the original, e.g. 1.2 - 1.4
+	// for >=1.2 or 1.2 for ^1.2 in the Cargo dialect
+	term string
+}
+
+// Comparator is a read-only view of a single comparison, such as >=1.2.0,
+// within parsed Constraints.
+type Comparator struct {
+	c *constraint
+}
+
+// Operator returns the comparison operator, one of "=", "!=", ">", ">=", "<"
+// or "<=", or "===" for the arbitrary equality of a PEP 440 specifier. The
+// "!" alias is reported as "!=".
+func (c Comparator) Operator() string {
+	return c.c.canonicalOperator()
+}
+
+// Version returns the version the comparator compares against.
+func (c Comparator) Version() Comparable {
+	return c.c.com
+}
+
+// Source returns the text the comparator was parsed from. Comparators
+// expanded from a range share the source of that range, e.g. both >=1.5.0
+// and <2.0.0 report ^1.5.
+func (c Comparator) Source() string {
+	return c.c.original
+}
+
+// Check tests if a version satisfies the comparator.
+func (c Comparator) Check(ver Comparable) bool {
+	return operatorsMap[c.c.operator](ver, c.c)
+}
+
+// String returns the comparator in canonical form, e.g. >=1.5.0.
+func (c Comparator) String() string {
+	return c.c.canonical()
+}
+
+// failure explains why a version does not satisfy the comparator.
+func (c *constraint) failure(ver Comparable) error {
+	var reason string
+	switch c.canonicalOperator() {
+	case OperatorGte:
+		reason = "is less than"
+	case OperatorGt:
+		reason = "is less than or equal to"
+	case OperatorLte:
+		reason = "is greater than"
+	case OperatorLt:
+		reason = "is greater than or equal to"
+	case OperatorEq:
+		reason = "is not equal to"
+	case pep440Arbitrary:
+		return fmt.Errorf("%s is not the string %s (from %s)", writtenVersion(ver), c.version, c.original)
+	default:
+		reason = "is equal to"
+	}
+	return fmt.Errorf("%s %s %s (from %s)", formatVersion(ver), reason, formatVersion(c.com), c.original)
+}
+
+// source returns the term the comparator was written in.
+func (c *constraint) source() string {
+	if c.term != "" {
+		return c.term
+	}
+	return c.original
+}
+
+// canonicalOperator returns the operator with aliases resolved.
+func (c *constraint) canonicalOperator() string {
+	if c.operator == "!" {
+		return OperatorNe
+	}
+	return c.operator
+}
+
+// canonical returns the comparator in canonical form, e.g. >=1.2.0.
+func (c *constraint) canonical() string {
+	op := c.canonicalOperator()
+	switch op {
+	case OperatorEq:
+		op = ""
+	case pep440Arbitrary:
+		return op + c.version
+	}
+	return op + formatVersion(c.com)
+}
+
+// splitOperator splits a single comparison such as >=1.2.x into its
+// operator and version. The operator is empty when none was written.
+func splitOperator(c string) (string, string) {
+	found := findConstraintRegex.FindStringSubmatch(c)
+	if len(found) < 3 {
+		return "", c
+	}
+	return found[1], found[2]
+}
+
+// hasWildcard tests if any of the major, minor or patch parts of a version
+// is a wildcard.
+func hasWildcard(ver string) bool {
+	if i := strings.IndexAny(ver, "-+"); i >= 0 {
+		ver = ver[:i]
+	}
+	for _, v := range strings.Split(ver, ".") {
+		if v == VersionAll || v == VersionX || v == "X" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatVersion returns the full textual form of a Comparable, including
+// its prerelease.
+func formatVersion(v Comparable) string {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.Prerelease() != "" {
+		return v.Version() + "-" + v.Prerelease()
+	}
+	return v.Version()
 }
 
-func parseConstraint(c string, fn New) ([]*constraint, error) {
+// writtenVersion returns the version as it was written when it is known,
+// e.g. 1.0.0 rather than 1.0 for a PEP440 parsed from "1.0.0".
+func writtenVersion(v Comparable) string {
+	if o, ok := v.(interface{ Original() string }); ok {
+		return o.Original()
+	}
+	return formatVersion(v)
+}
+
+func parseConstraint(c string, fn New, d Dialect) ([]*constraint, error) {
 	// replace x to 0
 	// c = strings.ReplaceAll(c, "x", "0")
 
 	valid := findConstraintRegex.MatchString(c)
 	if !valid {
-		return nil, ErrInvalidConstraint
+		return nil, constraintSyntaxError(c)
 	}
 	var result []*constraint
 	var ver, op string
@@ -184,38 +493,55 @@ func parseConstraint(c string, fn New) ([]*constraint, error) {
 		}
 		ver = found[2]
 	} else {
-		return nil, ErrInvalidConstraint
+		return nil, constraintSyntaxError(c)
 	}
 
 	if op == OperatorCaret {
-		result, err = parseCaretConstraint(c, ver, fn)
-		if err != nil {
-			return nil, err
-		}
+		result, err = parseCaretConstraint(c, ver, fn, d)
 	} else if op == OperatorTilde {
-		result, err = parseTildeConstraint(c, ver, fn)
-		if err != nil {
-			return nil, err
-		}
-	} else if strings.Contains(ver, VersionAll) || strings.Contains(ver, "x") {
-		result, err = parseStarConstraint(c, ver, fn)
-		if err != nil {
-			return nil, err
-		}
+		result, err = parseTildeConstraint(c, ver, fn, d)
+	} else if hasWildcard(ver) {
+		result, err = parseStarConstraint(c, op, ver, fn)
 	} else {
 		if ver == VersionAll {
 			op = OperatorGte
 			ver = VersionMinimum
 		}
-		com, err := fn(ver)
-		if err != nil {
-			return nil, err
+		var com Comparable
+		com, err = fn(ver)
+		if err == nil {
+			result = append(result, &constraint{original: c, version: ver, operator: op, com: com})
 		}
-		result = append(result, &constraint{original: c, version: ver, operator: op, com: com})
+	}
+	if err != nil {
+		return nil, relocate(err, c, len(c)-len(ver), ver)
 	}
 	return result, nil
 }
 
+// constraintSyntaxError locates the part of a single comparison that is not
+// an operator followed by a version.
+func constraintSyntaxError(c string) *ParseError {
+	offset := 0
+	for _, op := range []string{OperatorGte, OperatorLte, OperatorNe, "!", OperatorGt, OperatorLt,
+		OperatorCaret, OperatorTilde, OperatorEq} {
+		if strings.HasPrefix(c, op) {
+			offset = len(op)
+			break
+		}
+	}
+	if offset == len(c) {
+		return &ParseError{Input: c, Offset: offset, Reason: "missing version", Err: ErrInvalidConstraint}
+	}
+	for i, r := range c[offset:] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_.+*-", r) {
+			offset += i
+			break
+		}
+	}
+	return &ParseError{Input: c, Offset: offset, Token: c[offset:], Reason: "unexpected character", Err: ErrInvalidConstraint}
+}
+
 func constraintEqual(ver Comparable, c *constraint) bool {
 	return Compare(ver, c.com) == 0
 }
@@ -240,6 +566,12 @@ func constraintLessThanEqual(ver Comparable, c *constraint) bool {
 	return Compare(ver, c.com) <= 0
 }
 
+// constraintArbitraryEqual compares the version as written with the version
+// of the comparator, ignoring case.
+func constraintArbitraryEqual(ver Comparable, c *constraint) bool {
+	return strings.EqualFold(writtenVersion(ver), c.version)
+}
+
 // ^1.2.3  -->  >=1.2.3 <2.0.0
 // ^1.2    -->  >=1.2.0 <2.0.0
 // ^1      -->  >=1.0.0 <2.0.0
@@ -250,13 +582,21 @@ func constraintLessThanEqual(ver Comparable, c *constraint) bool {
 // ^0      -->  >=0.0.0 <1.0.0
 // ^0.x    -->  >=0.0.0 <1.0.0
 // ^1.x    -->  >=1.0.0 <2.0.0
-func parseCaretConstraint(original, ver string, fn New) ([]*constraint, error) {
+// ^*      -->  >=0.0.0
+//
+// Under DialectNPM a prerelease of 0.0.P stays below 0.0.P+1, e.g.
+// ^0.0.3-beta  -->  >=0.0.3-beta <0.0.4.
+func parseCaretConstraint(original, ver string, fn New, d Dialect) ([]*constraint, error) {
 	var result []*constraint
-	ver = strings.ReplaceAll(ver, VersionX, "0")
+	written := ver
+	ver, precision := expandWildcard(ver)
+	if precision == 0 {
+		return allVersions(original, fn)
+	}
 
 	ori, err := fn(ver)
 	if err != nil {
-		return nil, err
+		return nil, wildcardError(err, written, ver)
 	}
 	var max Comparable
 	if ori.Major() > 0 {
@@ -265,12 +605,15 @@ func parseCaretConstraint(original, ver string, fn New) ([]*constraint, error) {
 		max = ori.IncMinor()
 	} else if ori.Patch() > 0 {
 		max = ori.IncPatch()
+		if d == DialectNPM && ori.Prerelease() != "" {
+			// IncPatch of 0.0.3-beta is 0.0.3
+			max = max.IncPatch()
+		}
 	} else {
 		// version is ^0.0.0
-		vs := strings.Split(ver, ".")
-		if len(vs) == 1 {
+		if precision == 1 {
 			max = ori.IncMajor()
-		} else if len(vs) == 2 {
+		} else if precision == 2 {
 			max = ori.IncMinor()
 		} else {
 			max = ori.IncPatch()
@@ -288,15 +631,32 @@ func parseCaretConstraint(original, ver string, fn New) ([]*constraint, error) {
 // ~1.2, ~1.2.x      -->  >=1.2.0, <1.3.0
 // ~1.2.3,           -->  >=1.2.3, <1.3.0
 // ~1.2.0            -->  >=1.2.0, <1.3.0
-func parseTildeConstraint(original, ver string, fn New) ([]*constraint, error) {
+// ~1.0.x            -->  >=1.0.0, <1.1.0
+// ~*                -->  >=0.0.0
+//
+// The npm and Cargo dialects bump the minor part whenever it is written, so
+// ~1.0.0 is >=1.0.0, <1.1.0 there.
+func parseTildeConstraint(original, ver string, fn New, d Dialect) ([]*constraint, error) {
 	var result []*constraint
-	ver = strings.ReplaceAll(ver, VersionX, "0")
+	wildcard := hasWildcard(ver)
+	written := ver
+	ver, precision := expandWildcard(ver)
+	if precision == 0 {
+		return allVersions(original, fn)
+	}
 	ori, err := fn(ver)
 	if err != nil {
-		return nil, err
+		return nil, wildcardError(err, written, ver)
 	}
 	var max Comparable
-	if ori.Minor() == 0 && ori.Patch() == 0 {
+	if wildcard {
+		// x-ranges keep the parts that were written
+		if precision == 1 {
+			max = ori.IncMajor()
+		} else {
+			max = ori.IncMinor()
+		}
+	} else if precision == 1 || d == DialectDefault && ori.Minor() == 0 && ori.Patch() == 0 {
 		max = ori.IncMajor()
 	} else {
 		max = ori.IncMinor()
@@ -308,42 +668,154 @@ func parseTildeConstraint(original, ver string, fn New) ([]*constraint, error) {
 	return result, nil
 }
 
-// 2.*    -->  >=2.0.0, <3.0.0
-// 2.1.*  -->  >=2.1.0, <2.2.0
-func parseStarConstraint(original, ver string, fn New) ([]*constraint, error) {
-	var result []*constraint
-	var minorall, patchall bool
-	vs := strings.Split(ver, ".")
-	if len(vs) == 1 {
-		vs = append(vs, "0", "0")
-	} else if len(vs) == 2 {
-		if vs[1] == VersionAll || vs[1] == VersionX {
-			vs[1] = "0"
-			minorall = true
-		}
-	} else if len(vs) > 2 {
-		if vs[1] == VersionAll || vs[1] == VersionX {
-			minorall = true
-			vs[1] = "0"
-		} else if vs[2] == VersionAll || vs[2] == VersionX {
-			vs[2] = "0"
-			patchall = true
+// X-ranges follow node-semver. The x, X and * wildcards stand for any value
+// of their part and of all parts after it:
+//
+//	1.2.x, =1.2.x  -->  >=1.2.0, <1.3.0
+//	2.*            -->  >=2.0.0, <3.0.0
+//	>1.2.x         -->  >=1.3.0
+//	>=1.2.x        -->  >=1.2.0
+//	<1.2.x         -->  <1.2.0
+//	<=2.x          -->  <3.0.0
+//	*, =*, >=*     -->  >=0.0.0
+//	<*, >*, !=*    -->  nothing
+//
+// !=1.2.x matches every version 1.2.x does not. As that is an OR of two
+// ranges, it is parsed as a negation of 1.2.x by the expression parser.
+func parseStarConstraint(original, op, ver string, fn New) ([]*constraint, error) {
+	written := ver
+	ver, precision := expandWildcard(ver)
+	if precision == 0 {
+		switch op {
+		case OperatorGt, OperatorLt, OperatorNe, "!":
+			return noVersions(original, fn)
+		default:
+			return allVersions(original, fn)
 		}
 	}
-	ver = strings.Join(vs, ".")
+	if op == OperatorNe || op == "!" {
+		return nil, &ParseError{Input: written, Token: written,
+			Reason: "negated wildcard outside of an expression", Err: ErrInvalidConstraint}
+	}
+
 	ori, err := fn(ver)
 	if err != nil {
-		return nil, err
+		return nil, wildcardError(err, written, ver)
 	}
 	var max Comparable
-	if minorall {
+	if precision == 1 {
 		max = ori.IncMajor()
-	} else if patchall {
+	} else {
 		max = ori.IncMinor()
 	}
-	result = append(result,
-		&constraint{version: ori.Version(), operator: OperatorGte, com: ori, original: original},
-		&constraint{version: max.Version(), operator: OperatorLt, com: max, original: original},
-	)
+
+	var result []*constraint
+	switch op {
+	case OperatorGt:
+		result = append(result, &constraint{version: max.Version(), operator: OperatorGte, com: max, original: original})
+	case OperatorGte:
+		result = append(result, &constraint{version: ori.Version(), operator: OperatorGte, com: ori, original: original})
+	case OperatorLt:
+		result = append(result, &constraint{version: ori.Version(), operator: OperatorLt, com: ori, original: original})
+	case OperatorLte:
+		result = append(result, &constraint{version: max.Version(), operator: OperatorLt, com: max, original: original})
+	default:
+		result = append(result,
+			&constraint{version: ori.Version(), operator: OperatorGte, com: ori, original: original},
+			&constraint{version: max.Version(), operator: OperatorLt, com: max, original: original},
+		)
+	}
 	return result, nil
 }
+
+// expandWildcard replaces the wildcard parts of a version with 0 and
+// returns it together with its precision, the number of parts written
+// before the first wildcard. Versions with a wildcard lose their
+// prerelease, versions without one are returned unchanged.
+func expandWildcard(ver string) (string, int) {
+	release := ver
+	if i := strings.IndexAny(ver, "-+"); i >= 0 {
+		release = ver[:i]
+	}
+	parts := strings.Split(release, ".")
+	precision := len(parts)
+	for k, v := range parts {
+		if v == VersionAll || v == VersionX || v == "X" {
+			precision = k
+			break
+		}
+	}
+	if precision == len(parts) {
+		return ver, precision
+	}
+	for k := precision; k < len(parts); k++ {
+		// Anything else is left for the version parser to reject.
+		if containsOnly(parts[k], allowedNum+"xX*") {
+			parts[k] = "0"
+		}
+	}
+	return strings.Join(parts, "."), precision
+}
+
+// wildcardError moves an error of the version parser from the expanded
+// version back to the version as it was written, so that the token of
+// 1.q.x is q.x rather than q.0.
+func wildcardError(err error, written, expanded string) error {
+	var pe *ParseError
+	if written == expanded || !errors.As(err, &pe) {
+		return err
+	}
+	moved := *pe
+	moved.Input = written
+	moved.Offset = unexpandOffset(pe.Offset, written, expanded)
+	moved.Token = written[moved.Offset:unexpandOffset(pe.Offset+len(pe.Token), written, expanded)]
+	return &moved
+}
+
+// unexpandOffset returns the offset in the written version of an offset in
+// its expansion. Both have the same parts, only the wildcard parts differ.
+func unexpandOffset(offset int, written, expanded string) int {
+	release := written
+	if i := strings.IndexAny(written, "-+"); i >= 0 {
+		release = written[:i]
+	}
+	wp, ep := strings.Split(release, "."), strings.Split(expanded, ".")
+	start, estart := 0, 0
+	for k := range ep {
+		if k < len(wp) && offset <= estart+len(ep[k]) {
+			rel := offset - estart
+			if rel == len(ep[k]) || rel > len(wp[k]) {
+				rel = len(wp[k])
+			}
+			return start + rel
+		}
+		if k < len(wp) {
+			start += len(wp[k]) + 1
+		}
+		estart += len(ep[k]) + 1
+	}
+	return len(release)
+}
+
+// allVersions returns the comparators matching every version, >=0.0.0.
+func allVersions(original string, fn New) ([]*constraint, error) {
+	min, err := fn(VersionMinimum)
+	if err != nil {
+		return nil, err
+	}
+	return []*constraint{
+		{version: VersionMinimum, operator: OperatorGte, com: min, original: original},
+	}, nil
+}
+
+// noVersions returns comparators no version can satisfy.
+func noVersions(original string, fn New) ([]*constraint, error) {
+	min, err := fn(VersionMinimum)
+	if err != nil {
+		return nil, err
+	}
+	return []*constraint{
+		{version: VersionMinimum, operator: OperatorGte, com: min, original: original},
+		{version: VersionMinimum, operator: OperatorLt, com: min, original: original},
+	}, nil
+}
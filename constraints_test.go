@@ -68,7 +68,7 @@ func TestParseConstraint(t *testing.T) {
 	for _, tc := range tests {
 		c, err := parseConstraint(tc.in, func(ver string) (Comparable, error) {
 			return NewSemverStr(ver)
-		})
+		}, DialectDefault)
 		if tc.err {
 			assert.Error(t, err)
 		} else {
@@ -180,10 +180,8 @@ func TestParseConstraintGroup(t *testing.T) {
 		}, false},
 		{">=1.2.x", []*constraint{
 			{version: "1.2.0", operator: ">=", original: ">=1.2.x"},
-			{version: "1.3.0", operator: "<", original: ">=1.2.x"},
 		}, false},
 		{"<=2.x", []*constraint{
-			{version: "2.0.0", operator: ">=", original: "<=2.x"},
 			{version: "3.0.0", operator: "<", original: "<=2.x"},
 		}, false},
 		{"*", []*constraint{
@@ -192,9 +190,9 @@ func TestParseConstraintGroup(t *testing.T) {
 	}
 	for _, tc := range tests {
 		var c []*constraint
-		err := parseConstraintGroup(tc.in, func(ver string) (Comparable, error) {
+		err := parseGroup(tc.in, func(ver string) (Comparable, error) {
 			return NewSemverStr(ver)
-		}, &c)
+		}, DialectDefault, &c)
 		if tc.err {
 			assert.Error(t, err)
 		} else {
@@ -443,3 +441,222 @@ func TestConstraintsCheckCalVerString(t *testing.T) {
 		}
 	}
 }
+
+func TestConstraintsString(t *testing.T) {
+	tests := []string{
+		">=1.1 <2",
+		"^1.2.3 || ~2.1",
+		"1.0.0 - 2.0.0 <=2.0.0",
+		">=1.1    <2    !=1.2.3",
+		"*",
+		"2.1.*",
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tc, c.String())
+	}
+}
+
+func TestConstraintsCanonical(t *testing.T) {
+	tests := []struct {
+		con      string
+		expected string
+	}{
+		{">=1.1 <2", ">=1.1.0 <2.0.0"},
+		{"<2 >=1.1", ">=1.1.0 <2.0.0"},
+		{"^1.2.3 || ~2.1", ">=1.2.3 <2.0.0 || >=2.1.0 <2.2.0"},
+		{"^1.2.3-beta.2", ">=1.2.3-beta.2 <2.0.0"},
+		{"1.0.0 - 2.0.0 <=2.0.0", ">=1.0.0 <=2.0.0"},
+		{"!1.5 =1.6 1.7", "1.6.0 1.7.0 !=1.5.0"},
+		{"2.1.*", ">=2.1.0 <2.2.0"},
+		{"*", ">=0.0.0"},
+		{">=1.2.0 ^1.2", ">=1.2.0 <2.0.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.con, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, c.Canonical())
+	}
+
+	// Two spellings of the same policy print identically.
+	a, err := NewConstraint("^1.2.3", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	assert.NoError(t, err)
+	b, err := NewConstraint("<2.0.0 >=1.2.3", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, a.Canonical(), b.Canonical())
+}
+
+func TestConstraintsGroups(t *testing.T) {
+	c, err := NewConstraint("^1.5 !4.1 || =2.0.0", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	assert.NoError(t, err)
+
+	groups := c.Groups()
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, 3, len(groups[0]))
+	assert.Equal(t, 1, len(groups[1]))
+
+	expected := [][]struct {
+		op     string
+		ver    string
+		source string
+	}{
+		{{">=", "1.5.0", "^1.5"}, {"<", "2.0.0", "^1.5"}, {"!=", "4.1.0", "!4.1"}},
+		{{"=", "2.0.0", "=2.0.0"}},
+	}
+	for k, g := range groups {
+		for k2, com := range g {
+			assert.Equal(t, expected[k][k2].op, com.Operator())
+			assert.Equal(t, expected[k][k2].ver, com.Version().Version())
+			assert.Equal(t, expected[k][k2].source, com.Source())
+		}
+	}
+
+	v, _ := NewSemverStr("1.6.0")
+	assert.True(t, groups[0][0].Check(v))
+	assert.True(t, groups[0][1].Check(v))
+	assert.False(t, groups[1][0].Check(v))
+	assert.Equal(t, "!=4.1.0", groups[0][2].String())
+}
+
+func TestConstraintsValidate(t *testing.T) {
+	tests := []struct {
+		con    string
+		ver    string
+		valid  bool
+		errors []string
+	}{
+		{"^1.5", "1.6.0", true, nil},
+		{"^1.5", "1.4.0", false, []string{
+			"1.4.0 is less than 1.5.0 (from ^1.5)",
+		}},
+		{"^1.5 || ~2.1", "2.3.0", false, []string{
+			"2.3.0 is greater than or equal to 2.0.0 (from ^1.5)",
+			"2.3.0 is greater than or equal to 2.2.0 (from ~2.1)",
+		}},
+		{">1.1 <=1.2 || =3.0.0 || !=1.0.0", "1.0.0", false, []string{
+			"1.0.0 is less than or equal to 1.1.0 (from >1.1)",
+			"1.0.0 is not equal to 3.0.0 (from =3.0.0)",
+			"1.0.0 is equal to 1.0.0 (from !=1.0.0)",
+		}},
+		{"<=1.2", "1.3.0-beta.1", false, []string{
+			"1.3.0-beta.1 is greater than 1.2.0 (from <=1.2)",
+		}},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		v, err := NewSemverStr(tc.ver)
+		assert.NoError(t, err)
+		ok, errs := c.Validate(v)
+		assert.Equal(t, tc.valid, ok)
+		assert.Equal(t, len(tc.errors), len(errs))
+		for k, e := range errs {
+			assert.EqualError(t, e, tc.errors[k])
+		}
+	}
+}
+
+func TestWildcardConformance(t *testing.T) {
+	// Expansions follow node-semver x-ranges.
+	tests := []struct {
+		con      string
+		expected string
+	}{
+		{"*", ">=0.0.0"},
+		{"x", ">=0.0.0"},
+		{"X", ">=0.0.0"},
+		{"=*", ">=0.0.0"},
+		{">=*", ">=0.0.0"},
+		{"<=X", ">=0.0.0"},
+		{"^*", ">=0.0.0"},
+		{"~x", ">=0.0.0"},
+		{">*", ">=0.0.0 <0.0.0"},
+		{"<x", ">=0.0.0 <0.0.0"},
+		{"!=*", ">=0.0.0 <0.0.0"},
+		{"!*", ">=0.0.0 <0.0.0"},
+
+		{"1.x", ">=1.0.0 <2.0.0"},
+		{"1.X", ">=1.0.0 <2.0.0"},
+		{"1.*", ">=1.0.0 <2.0.0"},
+		{"1.x.x", ">=1.0.0 <2.0.0"},
+		{"1.x.3", ">=1.0.0 <2.0.0"},
+		{"=1.2.x", ">=1.2.0 <1.3.0"},
+		{"1.2.X", ">=1.2.0 <1.3.0"},
+		{"v1.2.*", ">=1.2.0 <1.3.0"},
+
+		{">1.x", ">=2.0.0"},
+		{">1.2.x", ">=1.3.0"},
+		{">=1.X", ">=1.0.0"},
+		{">=1.2.x", ">=1.2.0"},
+		{"<1.x", "<1.0.0"},
+		{"<1.2.*", "<1.2.0"},
+		{"<=1.x", "<2.0.0"},
+		{"<=1.2.X", "<1.3.0"},
+
+		{"!=1.x", "<1.0.0 || >=2.0.0"},
+		{"!1.2.X", "<1.2.0 || >=1.3.0"},
+		{">=1.0.0 !=1.2.x <2", ">=1.0.0 <1.2.0 <2.0.0 || >=1.0.0 >=1.3.0 <2.0.0"},
+
+		{"^1.x", ">=1.0.0 <2.0.0"},
+		{"^1.2.X", ">=1.2.0 <2.0.0"},
+		{"^0.x", ">=0.0.0 <1.0.0"},
+		{"^0.0.x", ">=0.0.0 <0.1.0"},
+		{"^0.1.*", ">=0.1.0 <0.2.0"},
+		{"~1.x", ">=1.0.0 <2.0.0"},
+		{"~1.0.x", ">=1.0.0 <1.1.0"},
+		{"~1.2.*", ">=1.2.0 <1.3.0"},
+		{"~0.X", ">=0.0.0 <1.0.0"},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		assert.Equal(t, tc.expected, c.Canonical(), tc.con)
+	}
+
+	checks := []struct {
+		con   string
+		ver   string
+		valid bool
+	}{
+		{"!=1.2.x", "1.2.5", false},
+		{"!=1.2.x", "1.3.0", true},
+		{"!=1.2.x", "1.1.9", true},
+		{">1.2.x", "1.2.9", false},
+		{">1.2.x", "1.3.0", true},
+		{"<=2.x", "2.9.9", true},
+		{"<=2.x", "3.0.0", false},
+		{">*", "1.0.0", false},
+		{"!=*", "1.0.0", false},
+		{"!=*", "0.0.0-alpha", false},
+		{"<x", "0.0.0-alpha", false},
+		{"X", "1.0.0", true},
+		{"1.2.3-x.1", "1.2.3-x.1", true},
+		{"^1.2.3-x.1", "1.2.3-x.2", true},
+	}
+	for _, tc := range checks {
+		c := mustSemverConstraint(t, tc.con)
+		ok, err := c.CheckString(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, ok, "%s %s", tc.con, tc.ver)
+	}
+
+	for _, con := range []string{"1.x.y", ">=1.2.z", "1.x.x.x"} {
+		_, err := NewConstraint(con, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		})
+		assert.Error(t, err, con)
+	}
+}
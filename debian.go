@@ -0,0 +1,371 @@
+package vc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var _ Ordered = &DebianVersion{}
+
+// DebianVersion is the version of a Debian package, [epoch:]upstream[-revision],
+// e.g. 1:2.30-1ubuntu2. Versions are ordered like dpkg orders them: by epoch,
+// then by upstream version and revision, each compared with the verrevcmp
+// algorithm of dpkg, in which "~" sorts before anything, even the end of
+// the version, so 1.0~rc1 is below 1.0.
+type DebianVersion struct {
+	epoch    uint64
+	upstream string
+	revision string
+	original string
+}
+
+// NewDebianVersionStr parses a Debian package version and returns an
+// instance of DebianVersion or an error if unable to parse the version.
+// Parse failures are reported as a *ParseError wrapping ErrInvalidDebian.
+func NewDebianVersionStr(ver string) (*DebianVersion, error) {
+	fail := func(offset int, token, reason string) error {
+		return &ParseError{Input: ver, Offset: offset, Token: token, Reason: reason, Err: ErrInvalidDebian}
+	}
+	start := skipSpaces(ver, 0)
+	s := strings.TrimSpace(ver)
+	if s == "" {
+		return nil, fail(0, "", "empty version")
+	}
+	if i := strings.IndexAny(s, " \t\n\r"); i >= 0 {
+		return nil, fail(start+i, s[i:], "embedded space")
+	}
+
+	v := &DebianVersion{original: ver}
+	upstreamStart := start
+	if colon := strings.IndexByte(s, ':'); colon >= 0 {
+		if colon == 0 {
+			return nil, fail(start, "", "empty epoch")
+		}
+		epoch, err := strconv.ParseUint(s[:colon], 10, 31)
+		if err != nil {
+			return nil, fail(start, s[:colon], "epoch is not a number")
+		}
+		v.epoch = epoch
+		s = s[colon+1:]
+		upstreamStart += colon + 1
+	}
+	v.upstream = s
+	if hyphen := strings.LastIndexByte(s, '-'); hyphen >= 0 {
+		v.upstream, v.revision = s[:hyphen], s[hyphen+1:]
+		if v.revision == "" {
+			return nil, fail(upstreamStart+hyphen, "-", "empty revision")
+		}
+	}
+	if v.upstream == "" {
+		return nil, fail(upstreamStart, "", "empty upstream version")
+	}
+	if i := strings.IndexFunc(v.upstream, func(r rune) bool {
+		return !isAlnum(r) && !strings.ContainsRune(".-+~:", r)
+	}); i >= 0 {
+		return nil, fail(upstreamStart+i, v.upstream[i:], "invalid character in upstream version")
+	}
+	if i := strings.IndexFunc(v.revision, func(r rune) bool {
+		return !isAlnum(r) && !strings.ContainsRune(".+~", r)
+	}); i >= 0 {
+		offset := upstreamStart + len(v.upstream) + 1 + i
+		return nil, fail(offset, v.revision[i:], "invalid character in revision")
+	}
+	return v, nil
+}
+
+func isAlnum(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// String returns the version as [epoch:]upstream[-revision], without a zero
+// epoch.
+func (v *DebianVersion) String() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		b.WriteString(strconv.FormatUint(v.epoch, 10) + ":")
+	}
+	b.WriteString(v.upstream)
+	if v.revision != "" {
+		b.WriteString("-" + v.revision)
+	}
+	return b.String()
+}
+
+// Original returns the original value passed in to be parsed.
+func (v *DebianVersion) Original() string {
+	return v.original
+}
+
+// Epoch returns the epoch, 0 if the version has none.
+func (v *DebianVersion) Epoch() uint64 {
+	return v.epoch
+}
+
+// Upstream returns the upstream version, e.g. 2.30 of 1:2.30-1ubuntu2.
+func (v *DebianVersion) Upstream() string {
+	return v.upstream
+}
+
+// Revision returns the Debian revision, e.g. 1ubuntu2 of 1:2.30-1ubuntu2.
+func (v *DebianVersion) Revision() string {
+	return v.revision
+}
+
+// Version returns the upstream version.
+func (v *DebianVersion) Version() string {
+	return v.upstream
+}
+
+// Major returns the first number of the upstream version, e.g. 2 of
+// 2.30+dfsg.
+func (v *DebianVersion) Major() uint64 {
+	return v.part(0)
+}
+
+// Minor returns the second number of the upstream version, 0 if there is
+// none.
+func (v *DebianVersion) Minor() uint64 {
+	return v.part(1)
+}
+
+// Patch returns the third number of the upstream version, 0 if there is
+// none.
+func (v *DebianVersion) Patch() uint64 {
+	return v.part(2)
+}
+
+// part returns the i-th of the dot separated numbers the upstream version
+// starts with.
+func (v *DebianVersion) part(i int) uint64 {
+	rest := v.upstream
+	for k := 0; ; k++ {
+		n := len(rest) - len(strings.TrimLeft(rest, allowedNum))
+		if n == 0 {
+			return 0
+		}
+		if k == i {
+			num, _ := strconv.ParseUint(rest[:n], 10, 64)
+			return num
+		}
+		rest = rest[n:]
+		if !strings.HasPrefix(rest, ".") {
+			return 0
+		}
+		rest = rest[1:]
+	}
+}
+
+// Prerelease returns the part of the upstream version after a "~", e.g.
+// rc1 of 1.0~rc1, which sorts before 1.0.
+func (v *DebianVersion) Prerelease() string {
+	if i := strings.IndexByte(v.upstream, '~'); i >= 0 {
+		return v.upstream[i+1:]
+	}
+	return ""
+}
+
+// IncMajor produces the next major version, e.g. 3.0.0 after 2.30-1,
+// keeping the epoch.
+func (v *DebianVersion) IncMajor() Comparable {
+	return v.withUpstream(strconv.FormatUint(v.Major()+1, 10) + ".0.0")
+}
+
+// IncMinor produces the next minor version, e.g. 2.31.0 after 2.30-1,
+// keeping the epoch.
+func (v *DebianVersion) IncMinor() Comparable {
+	return v.withUpstream(strconv.FormatUint(v.Major(), 10) + "." + strconv.FormatUint(v.Minor()+1, 10) + ".0")
+}
+
+// IncPatch produces the next patch version, e.g. 2.30.1 after 2.30-1. A
+// prerelease produces its release, e.g. 1.0 after 1.0~rc1.
+func (v *DebianVersion) IncPatch() Comparable {
+	if i := strings.IndexByte(v.upstream, '~'); i >= 0 {
+		return v.withUpstream(v.upstream[:i])
+	}
+	return v.withUpstream(strconv.FormatUint(v.Major(), 10) + "." + strconv.FormatUint(v.Minor(), 10) + "." +
+		strconv.FormatUint(v.Patch()+1, 10))
+}
+
+func (v *DebianVersion) withUpstream(upstream string) *DebianVersion {
+	next := &DebianVersion{epoch: v.epoch, upstream: upstream}
+	next.original = next.String()
+	return next
+}
+
+// CompareTo compares the version to another one in the order of dpkg.
+// Versions of other types are compared by their major, minor, patch and
+// prerelease parts.
+func (v *DebianVersion) CompareTo(other Comparable) int {
+	o, ok := other.(*DebianVersion)
+	if !ok {
+		return compareParts(v, other)
+	}
+	if d := compareSegment(v.epoch, o.epoch); d != 0 {
+		return d
+	}
+	if d := verrevcmp(v.upstream, o.upstream); d != 0 {
+		return d
+	}
+	return verrevcmp(v.revision, o.revision)
+}
+
+// verrevcmp compares upstream versions or revisions like dpkg: alternating
+// runs of non-digits, compared character by character, and numbers.
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+	at := func(s string, k int) byte {
+		if k < len(s) {
+			return s[k]
+		}
+		return 0
+	}
+	isDigit := func(c byte) bool {
+		return c >= '0' && c <= '9'
+	}
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isDigit(a[i]) || j < len(b) && !isDigit(b[j]) {
+			ac, bc := debianOrder(at(a, i)), debianOrder(at(b, j))
+			if ac != bc {
+				return boolCompare(ac > bc, bc > ac)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = boolCompare(a[i] > b[j], b[j] > a[i])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// debianOrder returns the weight of a character outside of a number: "~"
+// sorts first, then the end of the string, letters and other characters.
+func debianOrder(c byte) int {
+	switch {
+	case c == 0 || c >= '0' && c <= '9':
+		return 0
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// Relation operators of Debian package dependencies.
+var debianOperators = map[string]string{
+	"<<": OperatorLt,
+	"<=": OperatorLte,
+	"=":  OperatorEq,
+	">=": OperatorGte,
+	">>": OperatorGt,
+}
+
+var debianRelationRegex = regexp.MustCompile(`^\s*(?:([a-z0-9][a-z0-9+.\-]+)\s*)?(\()?\s*(<<|<=|>=|>>|=|<|>)?\s*([^\s()]*)\s*(\))?\s*$`)
+
+// NewDebianRelation parses version relations of a Debian package
+// dependency into Constraints whose versions are DebianVersion, e.g.
+// ">= 2.30-1, << 3" or "libc6 (>= 2.34) | libc6 (= 2.31-0ubuntu9)".
+// Relations separated by "|" are joined with OR, which binds tighter than
+// the AND of ",", as in a Depends field.
+//
+// The operators are <<, <=, =, >= and >>. The obsolete < and > are rejected
+// rather than read as <= and >=. A relation may be wrapped in parentheses
+// and preceded by the package name, all relations must then name the same
+// package.
+func NewDebianRelation(rel string, opts ...Option) (*Constraints, error) {
+	o := newOptions(opts)
+	groups := [][]*constraint{{}}
+	pkg := ""
+	offset := 0
+	for _, clause := range strings.Split(rel, ",") {
+		var alternatives [][]*constraint
+		for _, text := range strings.Split(clause, "|") {
+			atom, name, err := parseDebianRelation(text)
+			if err != nil {
+				return nil, relocate(err, rel, offset, text)
+			}
+			if name != "" && pkg != "" && name != pkg {
+				return nil, &ParseError{Input: rel, Offset: offset + strings.Index(text, name), Token: name,
+					Reason: "relations name different packages", Err: ErrInvalidConstraint}
+			}
+			if name != "" {
+				pkg = name
+			}
+			alternatives = append(alternatives, []*constraint{atom})
+			offset += len(text) + 1
+		}
+		var err error
+		if groups, err = andGroups(groups, alternatives); err != nil {
+			return nil, relocate(err, rel, 0, "")
+		}
+	}
+	return &Constraints{
+		constraints: groups,
+		newfn: func(s string) (Comparable, error) {
+			return NewDebianVersionStr(s)
+		},
+		original: rel,
+		policy:   o.prerelease,
+		opts:     opts,
+		syntax:   "Debian relation",
+	}, nil
+}
+
+// parseDebianRelation parses a single relation such as ">= 2.30-1" or
+// "libc6 (>= 2.34)" and returns its comparator and package name.
+func parseDebianRelation(text string) (*constraint, string, error) {
+	trimmed := strings.TrimSpace(text)
+	fail := func(offset int, token, reason string) error {
+		return &ParseError{Input: text, Offset: offset, Token: token, Reason: reason, Err: ErrInvalidConstraint}
+	}
+	if trimmed == "" {
+		return nil, "", fail(len(text)-len(strings.TrimLeft(text, " \t\n\r")), "", "empty relation")
+	}
+	m := debianRelationRegex.FindStringSubmatch(text)
+	if m == nil || (m[2] == "") != (m[5] == "") || (m[1] != "" && m[2] == "") {
+		return nil, "", fail(strings.Index(text, trimmed), trimmed, "expected an operator followed by a version")
+	}
+	name, op, ver := m[1], m[3], m[4]
+	opOffset := strings.Index(text, "(") + 1
+	if opOffset == 0 {
+		opOffset = strings.Index(text, trimmed)
+	}
+	opOffset = skipSpaces(text, opOffset)
+	switch {
+	case op == "":
+		return nil, "", fail(opOffset, ver, "missing operator")
+	case op == "<" || op == ">":
+		return nil, "", fail(opOffset, op, "obsolete operator, use "+op+op+" or "+op+"=")
+	case ver == "":
+		return nil, "", fail(opOffset+len(op), "", "missing version")
+	}
+	verOffset := skipSpaces(text, opOffset+len(op))
+	com, err := NewDebianVersionStr(ver)
+	if err != nil {
+		return nil, "", relocate(err, text, verOffset, ver)
+	}
+	return &constraint{original: trimmed, version: ver, operator: debianOperators[op], com: com}, name, nil
+}
@@ -0,0 +1,214 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDebianVersionStr(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+		epoch    uint64
+		upstream string
+		revision string
+		err      bool
+	}{
+		{"1.0", "1.0", 0, "1.0", "", false},
+		{"1.0-1", "1.0-1", 0, "1.0", "1", false},
+		{"1:2.30-1ubuntu2", "1:2.30-1ubuntu2", 1, "2.30", "1ubuntu2", false},
+		{"0:2.30", "2.30", 0, "2.30", "", false},
+		{"2.30-rc1-2", "2.30-rc1-2", 0, "2.30-rc1", "2", false},
+		{"1:2.30:1-2", "1:2.30:1-2", 1, "2.30:1", "2", false},
+		{"1.0~rc1+dfsg-0.1", "1.0~rc1+dfsg-0.1", 0, "1.0~rc1+dfsg", "0.1", false},
+		{" 1.0-1 ", "1.0-1", 0, "1.0", "1", false},
+		{"", "", 0, "", "", true},
+		{"1.0 1", "", 0, "", "", true},
+		{":1.0", "", 0, "", "", true},
+		{"a:1.0", "", 0, "", "", true},
+		{"1.0-", "", 0, "", "", true},
+		{"-1", "", 0, "", "", true},
+		{"1:", "", 0, "", "", true},
+		{"1.0_1", "", 0, "", "", true},
+		{"1.0-1:2", "", 0, "", "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewDebianVersionStr(tc.version)
+		if tc.err {
+			assert.True(t, errors.Is(err, ErrInvalidDebian), tc.version)
+			continue
+		}
+		if assert.NoError(t, err, tc.version) {
+			assert.Equal(t, tc.expected, v.String())
+			assert.Equal(t, tc.epoch, v.Epoch())
+			assert.Equal(t, tc.upstream, v.Upstream())
+			assert.Equal(t, tc.revision, v.Revision())
+			assert.Equal(t, tc.version, v.Original())
+		}
+	}
+}
+
+func TestDebianVersionParts(t *testing.T) {
+	v, err := NewDebianVersionStr("1:2.30.4+dfsg-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), v.Major())
+	assert.Equal(t, uint64(30), v.Minor())
+	assert.Equal(t, uint64(4), v.Patch())
+	assert.Equal(t, "", v.Prerelease())
+	assert.Equal(t, "1:3.0.0", formatVersion(v.IncMajor()))
+	assert.Equal(t, "1:2.31.0", formatVersion(v.IncMinor()))
+	assert.Equal(t, "1:2.30.5", formatVersion(v.IncPatch()))
+
+	v, err = NewDebianVersionStr("1.0~rc1-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), v.Patch())
+	assert.Equal(t, "rc1", v.Prerelease())
+	assert.Equal(t, "1.0", formatVersion(v.IncPatch()))
+}
+
+func TestDebianVersionCompare(t *testing.T) {
+	// Ascending, each version is above all of the previous ones.
+	versions := []string{
+		"1.0~~",
+		"1.0~~a",
+		"1.0~",
+		"1.0~rc1",
+		"1.0",
+		"1.0-1",
+		"1.0-1ubuntu2",
+		"1.0-1ubuntu10",
+		"1.0-1.1",
+		"1.0a",
+		"1.0+dfsg",
+		"1.0.1",
+		"1.2",
+		"1.10",
+		"1.10a~",
+		"2.0",
+		"1:0.1",
+		"1:0.1-1",
+		"2:0",
+	}
+
+	for i := range versions {
+		for j := range versions {
+			v1, err := NewDebianVersionStr(versions[i])
+			assert.NoError(t, err)
+			v2, err := NewDebianVersionStr(versions[j])
+			assert.NoError(t, err)
+			expected := boolCompare(i > j, j > i)
+			assert.Equal(t, expected, Compare(v1, v2), "%s <=> %s", versions[i], versions[j])
+		}
+	}
+
+	equal := [][2]string{
+		{"1.0", "0:1.0"},
+		{"1.0", "1.0-0"},
+		{"1.001", "1.1"},
+		{"1.0-01", "1.0-1"},
+	}
+	for _, tc := range equal {
+		v1, err := NewDebianVersionStr(tc[0])
+		assert.NoError(t, err)
+		v2, err := NewDebianVersionStr(tc[1])
+		assert.NoError(t, err)
+		assert.Equal(t, 0, Compare(v1, v2), "%s <=> %s", tc[0], tc[1])
+	}
+}
+
+func TestNewDebianRelation(t *testing.T) {
+	tests := []struct {
+		rel      string
+		version  string
+		expected bool
+	}{
+		{">= 2.30-1", "2.30-1", true},
+		{">= 2.30-1", "2.30", false},
+		{">= 2.30-1", "1:1.0", true},
+		{">> 2.30", "2.30", false},
+		{">> 2.30", "2.30-1", true},
+		{"<< 2.30", "2.30~rc1", true},
+		{"<< 2.30", "2.30", false},
+		{"<= 2.30-1", "2.30-1", true},
+		{"<= 2.30-1", "2.30-1.1", false},
+		{"= 2.30-1", "2.30-1", true},
+		{"= 2.30-1", "0:2.30-1", true},
+		{"= 2.30-1", "2.30-2", false},
+		{">=2.30", "2.31", true},
+		{"(>= 2.30)", "2.31", true},
+		{"libc6 (>= 2.34)", "2.35-0ubuntu3", true},
+		{"libc6 (>= 2.34)", "2.31-0ubuntu9", false},
+		{">= 2.30, << 3", "2.99", true},
+		{">= 2.30, << 3", "3.0", false},
+		{"libc6 (>= 2.34) | libc6 (= 2.31-0ubuntu9)", "2.31-0ubuntu9", true},
+		{"libc6 (>= 2.34) | libc6 (= 2.31-0ubuntu9)", "2.32", false},
+		{">= 1.0 | = 0.5, << 2", "0.5", true},
+		{">= 1.0 | = 0.5, << 2", "2.0", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewDebianRelation(tc.rel)
+		if !assert.NoError(t, err, tc.rel) {
+			continue
+		}
+		ok, err := c.CheckString(tc.version)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, ok, "%s %s", tc.rel, tc.version)
+	}
+}
+
+func TestDebianRelationCanonical(t *testing.T) {
+	tests := []struct {
+		rel      string
+		expected string
+	}{
+		{">> 2.30", ">2.30"},
+		{"<< 1:2.30-1", "<1:2.30-1"},
+		{"= 2.30", "2.30"},
+		{"<< 3, >= 2.30", ">=2.30 <3"},
+		{">= 1.0 | = 0.5, << 2", ">=1.0 <2 || <2 0.5"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewDebianRelation(tc.rel)
+		if assert.NoError(t, err, tc.rel) {
+			assert.Equal(t, tc.expected, c.Canonical())
+			assert.Equal(t, tc.rel, c.String())
+		}
+	}
+}
+
+func TestNewDebianRelationError(t *testing.T) {
+	tests := []struct {
+		rel    string
+		offset int
+		token  string
+		err    error
+	}{
+		{"", 0, "", ErrInvalidConstraint},
+		{">= 1.0,", 7, "", ErrInvalidConstraint},
+		{">= 1.0 |", 8, "", ErrInvalidConstraint},
+		{"> 1.0", 0, ">", ErrInvalidConstraint},
+		{">= 1.0, < 2", 8, "<", ErrInvalidConstraint},
+		{"(1.0)", 1, "1.0", ErrInvalidConstraint},
+		{"(>= 1.0", 0, "(>= 1.0", ErrInvalidConstraint},
+		{"libc6 >= 1.0", 0, "libc6 >= 1.0", ErrInvalidConstraint},
+		{">=", 2, "", ErrInvalidConstraint},
+		{"libc6 (>= 2.34) | musl (>= 1.2)", 18, "musl", ErrInvalidConstraint},
+		{">= 1.0, << 1.0_1", 14, "_1", ErrInvalidDebian},
+	}
+
+	for _, tc := range tests {
+		_, err := NewDebianRelation(tc.rel)
+		var pe *ParseError
+		if assert.True(t, errors.As(err, &pe), tc.rel) {
+			assert.Equal(t, tc.rel, pe.Input)
+			assert.Equal(t, tc.offset, pe.Offset, tc.rel)
+			assert.Equal(t, tc.token, pe.Token, tc.rel)
+			assert.True(t, errors.Is(err, tc.err), tc.rel)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package vc
+
+import "strings"
+
+// Describe renders the constraints in plain English, e.g.
+// "^1.2.3 || ~2.1" is described as
+// "at least 1.2.3 and below 2.0.0, or at least 2.1.0 and below 2.2.0".
+//
+// Ranges are described by the comparators they expand to. Prereleases are
+// matched by their order, so "below 2.0.0" also admits 2.0.0-alpha, and
+// bounds that carry a prerelease are described with it, e.g.
+// "at least 1.2.3-beta.2". Under PrereleaseNPM, each group states which
+// prereleases it admits, e.g. "at least 1.0.0, excluding prereleases".
+func (c *Constraints) Describe() string {
+	if len(c.constraints) == 0 {
+		return "no version"
+	}
+	groups := make([]string, 0, len(c.constraints))
+	for _, g := range c.constraints {
+		groups = append(groups, describeGroup(g, c.policy))
+	}
+	return strings.Join(groups, ", or ")
+}
+
+func describeGroup(group []*constraint, policy PrereleasePolicy) string {
+	desc := "any version"
+	if len(group) > 0 {
+		sorted := canonicalOrder(group)
+		atoms := make([]string, 0, len(sorted))
+		for _, v := range sorted {
+			atoms = append(atoms, v.describe())
+		}
+		desc = strings.Join(atoms, " and ")
+	}
+	switch policy {
+	case PrereleaseIncludeAll:
+		return desc
+	case PrereleaseExclude:
+		return desc + ", excluding prereleases"
+	}
+	var releases []string
+	seen := make(map[string]bool)
+	for _, v := range group {
+		if v.com.Prerelease() == "" || seen[v.com.Version()] {
+			continue
+		}
+		seen[v.com.Version()] = true
+		releases = append(releases, v.com.Version())
+	}
+	if len(releases) == 0 {
+		return desc + ", excluding prereleases"
+	}
+	return desc + ", with prereleases of " + strings.Join(releases, " or ") + " only"
+}
+
+// describe renders a single comparator in plain English.
+func (c *constraint) describe() string {
+	ver := formatVersion(c.com)
+	switch c.canonicalOperator() {
+	case OperatorGte:
+		return "at least " + ver
+	case OperatorGt:
+		return "above " + ver
+	case OperatorLte:
+		return "at most " + ver
+	case OperatorLt:
+		return "below " + ver
+	case OperatorEq:
+		return "exactly " + ver
+	case pep440Arbitrary:
+		return "literally " + c.version
+	default:
+		return "not " + ver
+	}
+}
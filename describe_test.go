@@ -0,0 +1,33 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintsDescribe(t *testing.T) {
+	tests := []struct {
+		con      string
+		expected string
+	}{
+		{"^1.2.3 || ~2.1", "at least 1.2.3 and below 2.0.0, or at least 2.1.0 and below 2.2.0"},
+		{">1.1 <=1.2", "above 1.1.0 and at most 1.2.0"},
+		{"=1.5.0 || !=1.6", "exactly 1.5.0, or not 1.6.0"},
+		{"<2 >=1 !=1.5.0", "at least 1.0.0 and below 2.0.0 and not 1.5.0"},
+		{"^1.2.3-beta.2", "at least 1.2.3-beta.2 and below 2.0.0"},
+		{"1.0.0 - 2.0.0", "at least 1.0.0 and at most 2.0.0"},
+		{"*", "at least 0.0.0"},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		assert.Equal(t, tc.expected, c.Describe(), tc.con)
+	}
+
+	c := mustSemverConstraint(t, "<1.0.0 || >=1.0.0").Simplify()
+	assert.Equal(t, "any version", c.Describe())
+	c, err := mustSemverConstraint(t, "^1").Intersect(mustSemverConstraint(t, "^2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "no version", c.Describe())
+}
@@ -0,0 +1,109 @@
+package vc
+
+import "strings"
+
+// Dialect selects the syntax and semantics of constraint expressions.
+type Dialect int
+
+const (
+	// DialectDefault is the syntax described by NewConstraint.
+	DialectDefault Dialect = iota
+	// DialectNPM follows node-semver ranges:
+	//
+	//   - partial versions are x-ranges, so 1.2 is 1.2.x, >1.2 is >=1.3.0,
+	//     <=1 is <2.0.0 and the hyphen range 1.2 - 1.4 is >=1.2.0 <1.5.0
+	//   - ~1.0 is >=1.0.0 <1.1.0 and ~> is the same as ~
+	//   - an operator may be followed by spaces, as in >= 1.2.3
+	//   - an empty expression or OR group matches every version
+	//   - prereleases follow PrereleaseNPM unless WithPrereleasePolicy is
+	//     given
+	DialectNPM
+	// DialectCargo follows the version requirements of Cargo:
+	//
+	//   - comparisons are joined with commas, as in >=1.2, <1.5
+	//   - a bare version is a caret range, so 1.2.3 is ^1.2.3
+	//   - partial versions are x-ranges, so =1.2 is >=1.2.0 <1.3.0 and
+	//     ~1 is >=1.0.0 <2.0.0
+	//   - an operator may be followed by spaces, as in >= 1.2.3
+	//   - prereleases follow PrereleaseNPM unless WithPrereleasePolicy is
+	//     given, which is the rule Cargo applies
+	DialectCargo
+)
+
+// WithDialect sets the dialect the expression is written in.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.dialect = d
+	}
+}
+
+// prereleasePolicy returns the prerelease policy of the dialect.
+func (d Dialect) prereleasePolicy() PrereleasePolicy {
+	if d == DialectNPM || d == DialectCargo {
+		return PrereleaseNPM
+	}
+	return PrereleaseIncludeAll
+}
+
+// spacedOperators are the operators npm and Cargo allow spaces after,
+// longest first.
+var spacedOperators = []string{"~>", OperatorNe, OperatorGte, OperatorLte, OperatorGt, OperatorLt, OperatorEq,
+	OperatorTilde, OperatorCaret}
+
+// attachOperators rewrites the npm and Cargo spellings of a clause into the
+// default syntax without moving the versions: "~>" becomes " ~" and an
+// operator followed by spaces is moved next to its version, so ">= 1.2"
+// becomes " >=1.2". Offsets within the clause stay valid for error reports.
+func attachOperators(clause string) string {
+	var b strings.Builder
+	for i := 0; i < len(clause); {
+		op := ""
+		for _, v := range spacedOperators {
+			if strings.HasPrefix(clause[i:], v) {
+				op = v
+				break
+			}
+		}
+		if op == "" {
+			b.WriteByte(clause[i])
+			i++
+			continue
+		}
+		end := i + len(op)
+		for end < len(clause) && (clause[end] == ' ' || clause[end] == '\t') {
+			end++
+		}
+		spaces := end - i - len(op)
+		if op == "~>" {
+			op, spaces = OperatorTilde, spaces+1
+		}
+		b.WriteString(strings.Repeat(" ", spaces))
+		b.WriteString(op)
+		i = end
+	}
+	return b.String()
+}
+
+// padPartial turns the partial version of a single comparison into an
+// x-range, e.g. >1.2 into >1.2.x. Versions with a prerelease or metadata
+// are left for the version parser.
+func padPartial(c string) string {
+	op, ver := splitOperator(c)
+	if ver == "" || strings.ContainsAny(ver, "-+") || hasWildcard(ver) {
+		return c
+	}
+	if n := strings.Count(ver, "."); n < 2 {
+		return op + ver + strings.Repeat("."+VersionX, 2-n)
+	}
+	return c
+}
+
+// cargoRequirement rewrites a single Cargo comparison into the default
+// syntax: partial versions become x-ranges and a bare version becomes a
+// caret range. It returns the number of bytes inserted before the version.
+func cargoRequirement(c string) (string, int) {
+	if op, ver := splitOperator(c); op == "" && !hasWildcard(ver) {
+		return OperatorCaret + c, len(OperatorCaret)
+	}
+	return padPartial(c), 0
+}
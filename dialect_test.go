@@ -0,0 +1,423 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newNPMConstraint(c string) (*Constraints, error) {
+	return NewConstraint(c, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithDialect(DialectNPM))
+}
+
+// Range test vectors of node-semver (test/fixtures/range-include.js and
+// range-exclude.js), without the loose and includePrerelease cases.
+func TestNPMRangeInclude(t *testing.T) {
+	tests := [][2]string{
+		{"1.0.0 - 2.0.0", "1.2.3"},
+		{"^1.2.3+build", "1.2.3"},
+		{"^1.2.3+build", "1.3.0"},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3"},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3-pre.2"},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "2.4.3-alpha"},
+		{"1.2.3+asdf - 2.4.3+asdf", "1.2.3"},
+		{"1.0.0", "1.0.0"},
+		{">=*", "0.2.4"},
+		{"", "1.0.0"},
+		{"*", "1.2.3"},
+		{">=1.0.0", "1.0.0"},
+		{">=1.0.0", "1.0.1"},
+		{">=1.0.0", "1.1.0"},
+		{">1.0.0", "1.0.1"},
+		{">1.0.0", "1.1.0"},
+		{"<=2.0.0", "2.0.0"},
+		{"<=2.0.0", "1.9999.9999"},
+		{"<=2.0.0", "0.2.9"},
+		{"<2.0.0", "1.9999.9999"},
+		{"<2.0.0", "0.2.9"},
+		{">= 1.0.0", "1.0.0"},
+		{">=  1.0.0", "1.0.1"},
+		{">=   1.0.0", "1.1.0"},
+		{"> 1.0.0", "1.0.1"},
+		{">  1.0.0", "1.1.0"},
+		{"<=   2.0.0", "2.0.0"},
+		{"<= 2.0.0", "1.9999.9999"},
+		{"<=  2.0.0", "0.2.9"},
+		{"<    2.0.0", "1.9999.9999"},
+		{"<\t2.0.0", "0.2.9"},
+		{">=0.1.97", "0.1.97"},
+		{"0.1.20 || 1.2.4", "1.2.4"},
+		{">=0.2.3 || <0.0.1", "0.0.0"},
+		{">=0.2.3 || <0.0.1", "0.2.3"},
+		{">=0.2.3 || <0.0.1", "0.2.4"},
+		{"||", "1.3.4"},
+		{"2.x.x", "2.1.3"},
+		{"1.2.x", "1.2.3"},
+		{"1.2.x || 2.x", "2.1.3"},
+		{"1.2.x || 2.x", "1.2.3"},
+		{"x", "1.2.3"},
+		{"2.*.*", "2.1.3"},
+		{"1.2.*", "1.2.3"},
+		{"1.2.* || 2.*", "2.1.3"},
+		{"1.2.* || 2.*", "1.2.3"},
+		{"2", "2.1.2"},
+		{"2.3", "2.3.1"},
+		{"~0.0.1", "0.0.1"},
+		{"~0.0.1", "0.0.2"},
+		{"~x", "0.0.9"},
+		{"~2", "2.0.9"},
+		{"~2.4", "2.4.0"},
+		{"~2.4", "2.4.5"},
+		{"~>3.2.1", "3.2.2"},
+		{"~1", "1.2.3"},
+		{"~>1", "1.2.3"},
+		{"~> 1", "1.2.3"},
+		{"~1.0", "1.0.2"},
+		{"~1.0.0", "1.0.5"},
+		{"~ 1.0", "1.0.2"},
+		{"~ 1.0.3", "1.0.12"},
+		{">=1", "1.0.0"},
+		{">= 1", "1.0.0"},
+		{"<1.2", "1.1.1"},
+		{"< 1.2", "1.1.1"},
+		{"~v0.5.4-pre", "0.5.5"},
+		{"~v0.5.4-pre", "0.5.4"},
+		{"=0.7.x", "0.7.2"},
+		{"<=0.7.x", "0.7.2"},
+		{">=0.7.x", "0.7.2"},
+		{"<=0.7.x", "0.6.2"},
+		{"~1.2.1 >=1.2.3", "1.2.3"},
+		{"~1.2.1 =1.2.3", "1.2.3"},
+		{"~1.2.1 1.2.3", "1.2.3"},
+		{"~1.2.1 >=1.2.3 1.2.3", "1.2.3"},
+		{"~1.2.1 1.2.3 >=1.2.3", "1.2.3"},
+		{">=1.2.1 1.2.3", "1.2.3"},
+		{"1.2.3 >=1.2.1", "1.2.3"},
+		{">=1.2.3 >=1.2.1", "1.2.3"},
+		{">=1.2.1 >=1.2.3", "1.2.3"},
+		{">=1.2", "1.2.8"},
+		{"^1.2.3", "1.8.1"},
+		{"^0.1.2", "0.1.2"},
+		{"^0.1", "0.1.2"},
+		{"^0.0.1", "0.0.1"},
+		{"^1.2", "1.4.2"},
+		{"^1.2 ^1", "1.4.2"},
+		{"^1.2.3-alpha", "1.2.3-pre"},
+		{"^1.2.0-alpha", "1.2.0-pre"},
+		{"^0.0.1-alpha", "0.0.1-beta"},
+		{"^0.0.1-alpha", "0.0.1"},
+		{"^0.1.1-alpha", "0.1.1-beta"},
+		{"^x", "1.2.3"},
+		{"x - 1.0.0", "0.9.7"},
+		{"x - 1.x", "0.9.7"},
+		{"1.0.0 - x", "1.9.7"},
+		{"1.x - x", "1.9.7"},
+		{"<=7.x", "7.9.9"},
+	}
+
+	for _, tc := range tests {
+		c, err := newNPMConstraint(tc[0])
+		assert.NoError(t, err, tc[0])
+		if err != nil {
+			continue
+		}
+		ok, err := c.CheckString(tc[1])
+		assert.NoError(t, err)
+		assert.True(t, ok, "%q should include %s", tc[0], tc[1])
+	}
+}
+
+func TestNPMRangeExclude(t *testing.T) {
+	tests := [][2]string{
+		{"1.0.0 - 2.0.0", "2.2.3"},
+		{"1.2.3+asdf - 2.4.3+asdf", "1.2.3-pre.2"},
+		{"1.2.3+asdf - 2.4.3+asdf", "2.4.3-alpha"},
+		{"^1.2.3+build", "2.0.0"},
+		{"^1.2.3+build", "1.2.0"},
+		{"^1.2.3", "1.2.3-pre"},
+		{"^1.2", "1.2.0-pre"},
+		{">1.2", "1.3.0-beta"},
+		{"<=1.2.3", "1.2.3-beta"},
+		{"^1.2.3", "1.2.3-beta"},
+		{"=0.7.x", "0.7.0-asdf"},
+		{">=0.7.x", "0.7.0-asdf"},
+		{"<=0.7.x", "0.7.0-asdf"},
+		{"1.0.0", "1.0.1"},
+		{">=1.0.0", "0.0.0"},
+		{">=1.0.0", "0.0.1"},
+		{">=1.0.0", "0.1.0"},
+		{">1.0.0", "0.0.1"},
+		{">1.0.0", "0.1.0"},
+		{"<=2.0.0", "3.0.0"},
+		{"<=2.0.0", "2.9999.9999"},
+		{"<=2.0.0", "2.2.9"},
+		{"<2.0.0", "2.9999.9999"},
+		{"<2.0.0", "2.2.9"},
+		{">=0.1.97", "0.1.93"},
+		{"0.1.20 || 1.2.4", "1.2.3"},
+		{">=0.2.3 || <0.0.1", "0.0.3"},
+		{">=0.2.3 || <0.0.1", "0.2.2"},
+		{"2.x.x", "1.1.3"},
+		{"2.x.x", "3.1.3"},
+		{"1.2.x", "1.3.3"},
+		{"1.2.x || 2.x", "3.1.3"},
+		{"1.2.x || 2.x", "1.1.3"},
+		{"2.*.*", "1.1.3"},
+		{"2.*.*", "3.1.3"},
+		{"1.2.*", "1.3.3"},
+		{"1.2.* || 2.*", "3.1.3"},
+		{"1.2.* || 2.*", "1.1.3"},
+		{"2", "1.1.2"},
+		{"2.3", "2.4.1"},
+		{"~0.0.1", "0.1.0-alpha"},
+		{"~0.0.1", "0.1.0"},
+		{"~2.4", "2.5.0"},
+		{"~2.4", "2.3.9"},
+		{"~>3.2.1", "3.3.2"},
+		{"~>3.2.1", "3.2.0"},
+		{"~1", "0.2.3"},
+		{"~>1", "2.2.3"},
+		{"~1.0", "1.1.0"},
+		{"~1.0.0", "1.1.0"},
+		{"~1.0.0", "1.5.0"},
+		{"<1", "1.0.0"},
+		{">=1.2", "1.1.1"},
+		{"~v0.5.4-beta", "0.5.4-alpha"},
+		{"=0.7.x", "0.8.2"},
+		{">=0.7.x", "0.6.2"},
+		{"<0.7.x", "0.7.2"},
+		{"<1.2.3", "1.2.3-beta"},
+		{"=1.2.3", "1.2.3-beta"},
+		{">1.2", "1.2.8"},
+		{"^0.0.1", "0.0.2-alpha"},
+		{"^0.0.1", "0.0.2"},
+		{"^1.2.3", "2.0.0-alpha"},
+		{"^1.2.3", "1.2.2"},
+		{"^1.2", "1.1.9"},
+		{"^1.0.0", "2.0.0-rc1"},
+		{"1 - 2", "3.0.0-pre"},
+		{"1.0 - 2", "1.0.0-pre"},
+		{"1.1.x", "1.0.0-a"},
+		{"1.1.x", "1.1.0-a"},
+		{"1.1.x", "1.2.0-a"},
+		{"1.x", "1.0.0-a"},
+		{"1.x", "1.1.0-a"},
+		{"1.x", "1.2.0-a"},
+		{">=1.0.0 <1.1.0", "1.1.0"},
+		{">=1.0.0 <1.1.0", "1.1.0-pre"},
+		{">=1.0.0 <1.1.0-pre", "1.1.0-pre"},
+		{"^1.2.3", "2.0.0-pre"},
+	}
+
+	for _, tc := range tests {
+		c, err := newNPMConstraint(tc[0])
+		assert.NoError(t, err, tc[0])
+		if err != nil {
+			continue
+		}
+		ok, err := c.CheckString(tc[1])
+		assert.NoError(t, err)
+		assert.False(t, ok, "%q should exclude %s", tc[0], tc[1])
+	}
+}
+
+// Range desugaring vectors of node-semver (test/fixtures/range-parse.js),
+// in the canonical form of Constraints. node-semver writes exclusive upper
+// bounds as e.g. <2.0.0-0, which matches the same releases as <2.0.0.
+func TestNPMRangeParse(t *testing.T) {
+	tests := [][2]string{
+		{"1.0.0 - 2.0.0", ">=1.0.0 <=2.0.0"},
+		{"1 - 2", ">=1.0.0 <3.0.0"},
+		{"1.0 - 2.0", ">=1.0.0 <2.1.0"},
+		{"1.0.0", "1.0.0"},
+		{">=*", ">=0.0.0"},
+		{"", "*"},
+		{"*", ">=0.0.0"},
+		{">=1.0.0", ">=1.0.0"},
+		{">1.0.0", ">1.0.0"},
+		{"<=2.0.0", "<=2.0.0"},
+		{"1", ">=1.0.0 <2.0.0"},
+		{"<2.0.0", "<2.0.0"},
+		{">= 1.0.0", ">=1.0.0"},
+		{">1", ">=2.0.0"},
+		{"0.1.20 || 1.2.4", "0.1.20 || 1.2.4"},
+		{">=0.2.3 || <0.0.1", ">=0.2.3 || <0.0.1"},
+		{"2.x.x", ">=2.0.0 <3.0.0"},
+		{"1.2.x", ">=1.2.0 <1.3.0"},
+		{"x", ">=0.0.0"},
+		{"2", ">=2.0.0 <3.0.0"},
+		{"2.3", ">=2.3.0 <2.4.0"},
+		{"~2.4", ">=2.4.0 <2.5.0"},
+		{"~>3.2.1", ">=3.2.1 <3.3.0"},
+		{"~1", ">=1.0.0 <2.0.0"},
+		{"~1.0", ">=1.0.0 <1.1.0"},
+		{"~1.0.0", ">=1.0.0 <1.1.0"},
+		{"~0.0.1", ">=0.0.1 <0.1.0"},
+		{"^0", ">=0.0.0 <1.0.0"},
+		{"^0.1", ">=0.1.0 <0.2.0"},
+		{"^1.0", ">=1.0.0 <2.0.0"},
+		{"^1.2", ">=1.2.0 <2.0.0"},
+		{"^0.0.1", ">=0.0.1 <0.0.2"},
+		{"^0.0.1-beta", ">=0.0.1-beta <0.0.2"},
+		{"^0.1.2", ">=0.1.2 <0.2.0"},
+		{"^1.2.3", ">=1.2.3 <2.0.0"},
+		{"^1.2.3-beta.4", ">=1.2.3-beta.4 <2.0.0"},
+		{"<1", "<1.0.0"},
+		{"< 1", "<1.0.0"},
+		{">=1", ">=1.0.0"},
+		{">= 1", ">=1.0.0"},
+		{"<1.2", "<1.2.0"},
+		{"< 1.2", "<1.2.0"},
+		{"^ 1.2 ^ 1", ">=1.0.0 >=1.2.0 <2.0.0"},
+		{"1.2 - 3.4.5", ">=1.2.0 <=3.4.5"},
+		{"1.2.3 - 3.4", ">=1.2.3 <3.5.0"},
+		{"1.2 - 3.4", ">=1.2.0 <3.5.0"},
+		{">1.2", ">=1.3.0"},
+		{">X", ">=0.0.0 <0.0.0"},
+		{"<X", ">=0.0.0 <0.0.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := newNPMConstraint(tc[0])
+		assert.NoError(t, err, tc[0])
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc[1], c.Canonical(), tc[0])
+	}
+
+	// The default dialect keeps its own reading of partial versions.
+	c := mustSemverConstraint(t, "1.2 - 1.4")
+	ok, _ := c.CheckString("1.4.5")
+	assert.False(t, ok)
+	c, _ = newNPMConstraint("1.2 - 1.4")
+	ok, _ = c.CheckString("1.4.5")
+	assert.True(t, ok)
+}
+
+func TestNPMDialectOptions(t *testing.T) {
+	c, err := NewConstraint(">=1.0.0", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithDialect(DialectNPM), WithPrereleasePolicy(PrereleaseIncludeAll))
+	assert.NoError(t, err)
+	ok, _ := c.CheckString("2.0.0-alpha")
+	assert.True(t, ok)
+
+	_, err = newNPMConstraint(">= 1.2 <")
+	var pe *ParseError
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, 8, pe.Offset)
+}
+
+func newCargoConstraint(c string) (*Constraints, error) {
+	return NewConstraint(c, func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithDialect(DialectCargo))
+}
+
+// Version requirement test vectors of the semver crate used by Cargo
+// (tests/test_version_req.rs).
+func TestCargoRequirements(t *testing.T) {
+	tests := []struct {
+		req      string
+		matches  []string
+		excludes []string
+	}{
+		{"1.0.0", []string{"1.0.0", "1.0.1"}, []string{"0.9.9", "0.10.0", "0.1.0", "1.0.0-pre", "0.0.1"}},
+		{"=1.0.0", []string{"1.0.0"}, []string{"1.0.1", "0.9.9", "0.10.0", "0.1.0", "1.0.0-pre"}},
+		{"=0.9.0", []string{"0.9.0"}, []string{"0.9.1", "1.9.0", "0.0.9", "0.9.0-pre"}},
+		{"=0.1.0-beta2.a", []string{"0.1.0-beta2.a"}, []string{"0.9.1", "0.1.0", "0.1.1-beta2.a", "0.1.0-beta2"}},
+		{"=1.2", []string{"1.2.0", "1.2.9"}, []string{"1.1.9", "1.3.0"}},
+		{">= 1.0.0", []string{"1.0.0", "2.0.0"}, []string{"0.1.0", "0.0.1", "1.0.0-pre", "2.0.0-pre"}},
+		{">= 2.1.0-alpha2", []string{"2.1.0-alpha2", "2.1.0-alpha3", "2.1.0", "3.0.0"},
+			[]string{"2.0.0", "2.1.0-alpha1", "2.0.0-pre", "2.2.0-pre", "3.0.0-pre"}},
+		{"< 1.0.0", []string{"0.1.0", "0.0.1"}, []string{"1.0.0", "1.0.0-beta", "1.0.1", "0.9.9-alpha"}},
+		{"<= 2.1.0-alpha2", []string{"2.1.0-alpha2", "2.1.0-alpha1", "2.0.0", "1.0.0"},
+			[]string{"2.1.0", "2.2.0-alpha1", "2.0.0-alpha2", "1.0.0-alpha2"}},
+		{"> 0.0.9, <= 2.5.3", []string{"0.0.10", "1.0.0", "2.5.3"}, []string{"0.0.8", "2.5.4"}},
+		{"0.3.0, 0.4.0", nil, []string{"0.0.8", "0.3.0", "0.4.0"}},
+		{"<= 0.2.0, >= 0.5.0", nil, []string{"0.0.8", "0.3.0", "0.5.1"}},
+		{">=0.5.1-alpha3, <0.6", []string{"0.5.1-alpha3", "0.5.1-alpha4", "0.5.1-beta", "0.5.1", "0.5.5"},
+			[]string{"0.5.1-alpha1", "0.5.2-alpha3", "0.5.5-pre", "0.5.0-pre", "0.6.0", "0.6.0-pre"}},
+		{"~1", []string{"1.0.0", "1.0.1", "1.1.1"}, []string{"0.9.1", "2.9.0", "0.0.9"}},
+		{"~1.2", []string{"1.2.0", "1.2.1"}, []string{"1.1.1", "1.3.0", "0.0.9"}},
+		{"~1.0.0", []string{"1.0.0", "1.0.9"}, []string{"0.9.9", "1.1.0", "1.5.0"}},
+		{"~2.0", []string{"2.0.0", "2.0.9"}, []string{"1.9.9", "2.1.0", "3.0.0"}},
+		{"~1.2.2", []string{"1.2.2", "1.2.4"}, []string{"1.2.1", "1.9.0", "1.0.9", "2.0.1", "0.1.3"}},
+		{"~1.2.3-beta.2", []string{"1.2.3", "1.2.4", "1.2.3-beta.2", "1.2.3-beta.4"},
+			[]string{"1.3.3", "1.1.4", "1.2.3-beta.1", "1.2.4-beta.2"}},
+		{"^1", []string{"1.1.2", "1.1.0", "1.2.1", "1.0.1"}, []string{"0.9.1", "2.9.0", "0.1.4"}},
+		{"^1.1", []string{"1.1.2", "1.1.0", "1.2.1"}, []string{"0.9.1", "2.9.0", "1.0.1", "0.1.4"}},
+		{"^1.1.2", []string{"1.1.2", "1.1.4", "1.2.1"},
+			[]string{"0.9.1", "2.9.0", "1.1.1", "0.0.1", "1.1.2-alpha1", "1.1.3-alpha1", "2.9.0-alpha1"}},
+		{"^0.1.2", []string{"0.1.2", "0.1.4"},
+			[]string{"0.9.1", "2.9.0", "1.1.1", "0.1.0", "0.1.2-beta", "0.1.3-alpha", "0.2.0-pre"}},
+		{"^0.5.1-alpha3", []string{"0.5.1-alpha3", "0.5.1-alpha4", "0.5.1-beta", "0.5.1", "0.5.5"},
+			[]string{"0.5.1-alpha1", "0.5.2-alpha3", "0.5.5-pre", "0.5.0-pre", "0.6.0"}},
+		{"^0.0.2", []string{"0.0.2"}, []string{"0.9.1", "2.9.0", "1.1.1", "0.0.1", "0.1.4"}},
+		{"^0.0", []string{"0.0.2", "0.0.0"}, []string{"0.9.1", "2.9.0", "1.1.1", "0.1.4"}},
+		{"^0", []string{"0.9.1", "0.0.2", "0.0.0"}, []string{"2.9.0", "1.1.1"}},
+		{"^1.4.2-beta.5", []string{"1.4.2", "1.4.3", "1.4.2-beta.5", "1.4.2-beta.6", "1.4.2-c"},
+			[]string{"0.9.9", "2.0.0", "1.4.2-alpha", "1.4.2-beta.4", "1.4.3-beta.5"}},
+		{"*", []string{"0.9.1", "2.9.0", "0.0.9", "1.0.1", "1.1.1"}, []string{"1.0.0-pre"}},
+		{"1.*", []string{"1.2.0", "1.2.1", "1.1.1", "1.3.0"}, []string{"0.0.9", "2.0.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.2", "1.2.4"}, []string{"1.9.0", "1.0.9", "2.0.1", "0.1.3"}},
+	}
+
+	for _, tc := range tests {
+		c, err := newCargoConstraint(tc.req)
+		assert.NoError(t, err, tc.req)
+		if err != nil {
+			continue
+		}
+		for _, v := range tc.matches {
+			ok, err := c.CheckString(v)
+			assert.NoError(t, err)
+			assert.True(t, ok, "%q should match %s", tc.req, v)
+		}
+		for _, v := range tc.excludes {
+			ok, err := c.CheckString(v)
+			assert.NoError(t, err)
+			assert.False(t, ok, "%q should not match %s", tc.req, v)
+		}
+	}
+}
+
+func TestCargoRequirementsParse(t *testing.T) {
+	tests := [][2]string{
+		{"1.2.3", ">=1.2.3 <2.0.0"},
+		{"1.2", ">=1.2.0 <2.0.0"},
+		{"0.2", ">=0.2.0 <0.3.0"},
+		{">=1.2, <1.5", ">=1.2.0 <1.5.0"},
+		{">=1.2,<1.5", ">=1.2.0 <1.5.0"},
+		{"=1", ">=1.0.0 <2.0.0"},
+		{"> 1.2", ">=1.3.0"},
+		{"<= 1", "<2.0.0"},
+		{"~1.2.3", ">=1.2.3 <1.3.0"},
+		{"~2.0.0", ">=2.0.0 <2.1.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := newCargoConstraint(tc[0])
+		assert.NoError(t, err, tc[0])
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc[1], c.Canonical(), tc[0])
+	}
+
+	_, err := newCargoConstraint(">=1.2, 1.x.y")
+	var pe *ParseError
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, 11, pe.Offset)
+	assert.Equal(t, "y", pe.Token)
+
+	// Bare versions are read as caret ranges, errors still point at the
+	// written text.
+	_, err = newCargoConstraint("<2, 1.2.y")
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, 8, pe.Offset)
+	assert.Equal(t, "y", pe.Token)
+}
@@ -0,0 +1,52 @@
+package vc
+
+// ConstraintDiff describes how the versions allowed by constraints changed.
+type ConstraintDiff struct {
+	// Added and Removed are the versions of the available list that only
+	// the new or only the old constraints allow, in the order of the list.
+	Added   []Comparable
+	Removed []Comparable
+	// AddedIntervals and RemovedIntervals are the ranges of versions that
+	// only the new or only the old constraints match, regardless of the
+	// available versions.
+	AddedIntervals   []Interval
+	RemovedIntervals []Interval
+}
+
+// Empty reports whether the constraints match the same versions.
+func (d ConstraintDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.AddedIntervals) == 0 && len(d.RemovedIntervals) == 0
+}
+
+// DiffConstraints compares two constraints, e.g. the old and the new range
+// of a dependency. The versions of available are checked against both to
+// find those that became allowed or disallowed. With no available versions,
+// the symbolic interval diff alone describes the change.
+//
+// The interval diff is left empty for constraints the set operations
+// reject, such as constraints under PrereleaseNPM with a prerelease
+// comparator. DiffConstraints returns the error of the set operations for
+// them only when no available versions are given.
+func DiffConstraints(from, to *Constraints, available []Comparable) (ConstraintDiff, error) {
+	var d ConstraintDiff
+	for _, v := range available {
+		before, after := from.Check(v), to.Check(v)
+		switch {
+		case after && !before:
+			d.Added = append(d.Added, v)
+		case before && !after:
+			d.Removed = append(d.Removed, v)
+		}
+	}
+	if err := orderedSets(from, to); err != nil {
+		if available == nil {
+			return ConstraintDiff{}, err
+		}
+		return d, nil
+	}
+	a, b := from.set(), to.set()
+	d.AddedIntervals = b.intersect(a.complement()).intervals()
+	d.RemovedIntervals = a.intersect(b.complement()).intervals()
+	return d, nil
+}
@@ -0,0 +1,101 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConstraints(t *testing.T) {
+	available := []string{"1.1.0", "1.2.0", "1.4.5", "1.9.0", "2.0.0", "2.1.0", "3.0.0"}
+	tests := []struct {
+		from, to         string
+		added, removed   []string
+		addedI, removedI []string
+	}{
+		{"^1.2", "^1.4 || ^2", []string{"2.0.0", "2.1.0"}, []string{"1.2.0"},
+			[]string{"[2.0.0, 3.0.0)"}, []string{"[1.2.0, 1.4.0)"}},
+		{"^1.2", ">=1.2.0 <2.0.0", nil, nil, []string{}, []string{}},
+		{"^1", "^1 !=1.4.5", nil, []string{"1.4.5"}, []string{}, []string{"[1.4.5, 1.4.5]"}},
+		{"~1.2", "<1.0.0", nil, []string{"1.2.0"}, []string{"(-inf, 1.0.0)"}, []string{"[1.2.0, 1.3.0)"}},
+		{">=3", "*", []string{"1.1.0", "1.2.0", "1.4.5", "1.9.0", "2.0.0", "2.1.0"}, nil,
+			[]string{"[0.0.0, 3.0.0)"}, []string{}},
+	}
+
+	vs := make([]Comparable, 0, len(available))
+	for _, s := range available {
+		v, _ := NewSemverStr(s)
+		vs = append(vs, v)
+	}
+
+	for _, tc := range tests {
+		from, to := mustSemverConstraint(t, tc.from), mustSemverConstraint(t, tc.to)
+		d, err := DiffConstraints(from, to, vs)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.added, versionStrings(d.Added), "%s -> %s", tc.from, tc.to)
+		assert.Equal(t, tc.removed, versionStrings(d.Removed), "%s -> %s", tc.from, tc.to)
+		assert.Equal(t, tc.addedI, intervalStrings(d.AddedIntervals), "%s -> %s", tc.from, tc.to)
+		assert.Equal(t, tc.removedI, intervalStrings(d.RemovedIntervals), "%s -> %s", tc.from, tc.to)
+		assert.Equal(t, equivalent(t, from, to), d.Empty())
+
+		symbolic, err := DiffConstraints(from, to, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, symbolic.Added)
+		assert.Nil(t, symbolic.Removed)
+		assert.Equal(t, d.AddedIntervals, symbolic.AddedIntervals)
+	}
+}
+
+func TestDiffConstraintsPrerelease(t *testing.T) {
+	from := mustSemverConstraint(t, ">=1.0.0")
+	to, err := NewConstraint(">=1.0.0", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+
+	// The interval diff cannot express which prereleases PrereleaseIncludeAll
+	// admits and the npm policy does not, the versions are still checked.
+	v, _ := NewSemverStr("2.0.0-alpha")
+	d, err := DiffConstraints(from, to, []Comparable{v})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0-alpha"}, versionStrings(d.Removed))
+	assert.Nil(t, d.AddedIntervals)
+	assert.Nil(t, d.RemovedIntervals)
+
+	_, err = DiffConstraints(from, to, nil)
+	assert.ErrorIs(t, err, ErrPrereleasePolicy)
+}
+
+func TestDiffConstraintsPEP440(t *testing.T) {
+	from, err := NewPEP440Specifier(">=1.0")
+	assert.NoError(t, err)
+	to, err := NewPEP440Specifier(">=1.1")
+	assert.NoError(t, err)
+
+	var available []Comparable
+	for _, s := range []string{"1.0", "1.0.5", "1.1", "1.1rc1"} {
+		v, _ := NewPEP440Str(s)
+		available = append(available, v)
+	}
+	d, err := DiffConstraints(from, to, available)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0", "1.0.5"}, versionStrings(d.Removed))
+	assert.Nil(t, d.Added)
+	assert.Equal(t, []string{"[1.0, 1.1)"}, intervalStrings(d.RemovedIntervals))
+}
+
+func versionStrings(vs []Comparable) []string {
+	var result []string
+	for _, v := range vs {
+		result = append(result, formatVersion(v))
+	}
+	return result
+}
+
+func intervalStrings(is []Interval) []string {
+	result := make([]string, 0, len(is))
+	for _, i := range is {
+		result = append(result, i.String())
+	}
+	return result
+}
@@ -1,6 +1,9 @@
 package vc
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrInvalidSemVer is returned a version is found to be invalid when
@@ -11,6 +14,18 @@ var (
 	// being parsed.
 	ErrInvalidCalVer = errors.New("invalid calendar version")
 
+	// ErrInvalidPEP440 is returned a version is found to be invalid when
+	// being parsed as a PEP 440 version.
+	ErrInvalidPEP440 = errors.New("invalid PEP 440 version")
+
+	// ErrInvalidMaven is returned a version is found to be invalid when
+	// being parsed as a Maven version.
+	ErrInvalidMaven = errors.New("invalid Maven version")
+
+	// ErrInvalidDebian is returned a version is found to be invalid when
+	// being parsed as a Debian package version.
+	ErrInvalidDebian = errors.New("invalid Debian version")
+
 	// ErrInvalidConstraint is returned a constraint is found to be invalid when
 	// being parsed.
 	ErrInvalidConstraint = errors.New("invalid constraint")
@@ -24,4 +39,68 @@ var (
 
 	// ErrInvalidPrerelease is returned when the pre-release is an invalid format
 	ErrInvalidPrerelease = errors.New("invalid prerelease string")
+
+	// ErrPrereleasePolicy is returned by the set operations on Constraints
+	// whose prerelease policy admits prereleases by comparator, as the
+	// versions they match are not ranges ordered by Compare, or on
+	// Constraints whose policies match prereleases and releases only.
+	ErrPrereleasePolicy = errors.New("prerelease policy does not match ranges of versions")
+
+	// ErrArbitraryEquality is returned by the set operations on Constraints
+	// with a PEP 440 "===" comparator, which matches versions by how they
+	// are written rather than by their order.
+	ErrArbitraryEquality = errors.New("set operations do not support ===")
+
+	// ErrUnsupportedSyntax is returned by Widen for constraints that cannot
+	// be written back in the syntax they were parsed from.
+	ErrUnsupportedSyntax = errors.New("constraints cannot be rewritten in their syntax")
+
+	// ErrUnknownScheme is returned by ConstraintCache for a version scheme
+	// that was not registered.
+	ErrUnknownScheme = errors.New("unknown version scheme")
 )
+
+// ParseError describes why a version or a constraint could not be parsed.
+// It wraps one of the sentinel errors above, so it can be tested with
+// errors.Is, e.g. errors.Is(err, ErrInvalidConstraint).
+type ParseError struct {
+	// Input is the whole string that was being parsed.
+	Input string
+	// Offset is the byte offset of Token within Input.
+	Offset int
+	// Token is the offending part of Input.
+	Token string
+	// Reason explains what is wrong with Token.
+	Reason string
+	// Err is the underlying sentinel error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s %q: %s at offset %d", e.Err, e.Input, e.Reason, e.Offset)
+	}
+	return fmt.Sprintf("%s %q: %s at offset %d: %q", e.Err, e.Input, e.Reason, e.Offset, e.Token)
+}
+
+// Unwrap returns the underlying sentinel error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// relocate returns a copy of a ParseError found in a part of input that
+// starts at offset, with Input and Offset referring to input. Other errors
+// are wrapped into a ParseError for the token at offset.
+func relocate(err error, input string, offset int, token string) error {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		return &ParseError{Input: input, Offset: offset, Token: token, Reason: err.Error(), Err: err}
+	}
+	moved := *pe
+	moved.Input = input
+	moved.Offset = offset + pe.Offset
+	if moved.Offset < 0 {
+		moved.Offset = 0
+	}
+	return &moved
+}
@@ -0,0 +1,93 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	newSemver := func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}
+	newCalVer := func(s string) (Comparable, error) {
+		return NewCalVerStr(s)
+	}
+	tests := []struct {
+		name   string
+		parse  func() error
+		input  string
+		offset int
+		token  string
+		is     error
+	}{
+		{"semver", func() error { _, err := NewSemverStr("1.2.beta"); return err },
+			"1.2.beta", 4, "beta", ErrInvalidSemVer},
+		{"semver extra segment", func() error { _, err := NewSemverStr("v1.2.3.4"); return err },
+			"v1.2.3.4", 6, ".4", ErrInvalidSemVer},
+		{"semver empty prerelease", func() error { _, err := NewSemverStr("1.2.3-"); return err },
+			"1.2.3-", 6, "", ErrInvalidSemVer},
+		{"semver leading zero", func() error { _, err := NewSemverStr("v1.2.3-alpha.01"); return err },
+			"v1.2.3-alpha.01", 13, "01", ErrSegmentStartsZero},
+		{"semver leading zero inside", func() error { _, err := NewSemverStr("1.2.3-alpha.01.beta"); return err },
+			"1.2.3-alpha.01.beta", 12, "01", ErrSegmentStartsZero},
+		{"semver overflow", func() error { _, err := NewSemverStr("1.99999999999999999999"); return err },
+			"1.99999999999999999999", 2, "99999999999999999999", ErrInvalidSemVer},
+		{"calver year", func() error { _, err := NewCalVerStr("202.7.23"); return err },
+			"202.7.23", 0, "202", ErrInvalidCalVer},
+		{"calver day", func() error { _, err := NewCalVerStr("2023.7.202"); return err },
+			"2023.7.202", 7, "202", ErrInvalidCalVer},
+		{"calver metadata", func() error { _, err := NewCalVerStr("2023.11.13-dev+1"); return err },
+			"2023.11.13-dev+1", 14, "+1", ErrInvalidCalVer},
+		{"constraint version", func() error { _, err := NewConstraint(">=1.2 <2.x.y || ~abc", newSemver); return err },
+			">=1.2 <2.x.y || ~abc", 11, "y", ErrInvalidSemVer},
+		{"constraint tilde", func() error { _, err := NewConstraint(">=1.2 <2 || ~abc", newSemver); return err },
+			">=1.2 <2 || ~abc", 13, "abc", ErrInvalidSemVer},
+		{"constraint operator", func() error { _, err := NewConstraint("^1 || =<1.2", newSemver); return err },
+			"^1 || =<1.2", 7, "<1.2", ErrInvalidConstraint},
+		{"constraint missing version", func() error { _, err := NewConstraint("^1 >=", newSemver); return err },
+			"^1 >=", 5, "", ErrInvalidConstraint},
+		{"constraint hyphen", func() error { _, err := NewConstraint("1 - 2 - 3", newSemver); return err },
+			"1 - 2 - 3", 5, "-", ErrInvalidConstraint},
+		{"constraint hyphen end", func() error { _, err := NewConstraint("1 - 2.y", newSemver); return err },
+			"1 - 2.y", 6, "y", ErrInvalidSemVer},
+		{"constraint parenthesis", func() error { _, err := NewConstraint("(^1 || ^2", newSemver); return err },
+			"(^1 || ^2", 9, "", ErrInvalidConstraint},
+		{"constraint operand", func() error { _, err := NewConstraint("^1 || || ^2", newSemver); return err },
+			"^1 || || ^2", 6, "||", ErrInvalidConstraint},
+		{"constraint empty", func() error { _, err := NewConstraint("  ", newSemver); return err },
+			"  ", 0, "", ErrInvalidConstraint},
+		{"constraint wildcard", func() error { _, err := NewConstraint(">=1.2 !=1.q.x", newSemver); return err },
+			">=1.2 !=1.q.x", 10, "q.x", ErrInvalidSemVer},
+		{"constraint negated wildcard", func() error {
+			_, err := NewConstraint(">=1 != 1.2.x", newSemver, WithDialect(DialectNPM))
+			return err
+		}, ">=1 != 1.2.x", 7, "1.2.x", ErrInvalidConstraint},
+		{"calver constraint", func() error { _, err := NewConstraint(">2023.1 <2023.13.400", newCalVer); return err },
+			">2023.1 <2023.13.400", 17, "400", ErrInvalidCalVer},
+	}
+
+	for _, tc := range tests {
+		err := tc.parse()
+		var pe *ParseError
+		if !assert.True(t, errors.As(err, &pe), tc.name) {
+			continue
+		}
+		assert.Equal(t, tc.input, pe.Input, tc.name)
+		assert.Equal(t, tc.offset, pe.Offset, tc.name)
+		assert.Equal(t, tc.token, pe.Token, tc.name)
+		assert.NotEmpty(t, pe.Reason, tc.name)
+		assert.True(t, errors.Is(err, tc.is), tc.name)
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	_, err := NewSemverStr("1.2.beta")
+	assert.EqualError(t, err, `invalid semantic version "1.2.beta": expected a number at offset 4: "beta"`)
+
+	_, err = NewConstraint("(^1", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	})
+	assert.EqualError(t, err, `invalid constraint "(^1": missing ")" at offset 3`)
+}
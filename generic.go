@@ -0,0 +1,89 @@
+package vc
+
+import "strings"
+
+// Constraint is a type-safe view of Constraints that only accepts versions
+// of type T, e.g. a Constraint[*Semver] cannot be checked against a CalVer.
+type Constraint[T Comparable] struct {
+	c     *Constraints
+	parse func(string) (T, error)
+}
+
+// ParseConstraint parses an expression with the syntax of NewConstraint,
+// using fn to parse the versions it contains.
+func ParseConstraint[T Comparable](c string, fn func(string) (T, error), opts ...Option) (*Constraint[T], error) {
+	o := newOptions(opts)
+	if strings.TrimSpace(c) == "" && o.dialect != DialectNPM {
+		return nil, &ParseError{Input: c, Reason: "empty constraint", Err: ErrInvalidConstraint}
+	}
+	newfn := func(s string) (Comparable, error) {
+		v, err := fn(s)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	gcs, err := parseExpression(c, newfn, o)
+	if err != nil {
+		return nil, err
+	}
+	return &Constraint[T]{
+		c:     &Constraints{constraints: gcs, newfn: newfn, original: c, policy: o.prerelease, opts: opts},
+		parse: fn,
+	}, nil
+}
+
+// ParseSemverConstraint parses an expression whose versions are semantic
+// versions.
+func ParseSemverConstraint(c string, opts ...Option) (*Constraint[*Semver], error) {
+	return ParseConstraint(c, NewSemverStr, opts...)
+}
+
+// ParseCalVerConstraint parses an expression whose versions are calendar
+// versions.
+func ParseCalVerConstraint(c string, opts ...Option) (*Constraint[*CalVer], error) {
+	return ParseConstraint(c, NewCalVerStr, opts...)
+}
+
+// Check tests if a version satisfies the constraints.
+func (c *Constraint[T]) Check(ver T) bool {
+	return c.c.Check(ver)
+}
+
+// CheckString parses a version of type T and tests if it satisfies the
+// constraints.
+func (c *Constraint[T]) CheckString(ver string) (bool, error) {
+	v, err := c.parse(ver)
+	if err != nil {
+		return false, err
+	}
+	return c.c.Check(v), nil
+}
+
+// Validate checks a version against the constraints and explains a failure,
+// see Constraints.Validate.
+func (c *Constraint[T]) Validate(ver T) (bool, []error) {
+	return c.c.Validate(ver)
+}
+
+// String returns the expression the constraints were parsed from.
+func (c *Constraint[T]) String() string {
+	return c.c.String()
+}
+
+// Canonical returns the normalized form of the constraints, see
+// Constraints.Canonical.
+func (c *Constraint[T]) Canonical() string {
+	return c.c.Canonical()
+}
+
+// Describe renders the constraints in plain English.
+func (c *Constraint[T]) Describe() string {
+	return c.c.Describe()
+}
+
+// Constraints returns the untyped constraints, e.g. for the set operations
+// and Lint. It shares its state with c.
+func (c *Constraint[T]) Constraints() *Constraints {
+	return c.c
+}
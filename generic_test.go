@@ -0,0 +1,75 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSemverConstraint(t *testing.T) {
+	c, err := ParseSemverConstraint("^1.2 || >=3")
+	assert.NoError(t, err)
+	assert.Equal(t, "^1.2 || >=3", c.String())
+	assert.Equal(t, ">=1.2.0 <2.0.0 || >=3.0.0", c.Canonical())
+
+	tests := []struct {
+		ver   string
+		valid bool
+	}{
+		{"1.2.0", true},
+		{"2.0.0", false},
+		{"3.1.0", true},
+	}
+	for _, tc := range tests {
+		v, err := NewSemverStr(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, c.Check(v), tc.ver)
+		ok, err := c.CheckString(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, ok, tc.ver)
+	}
+
+	_, err = c.CheckString("latest")
+	assert.ErrorIs(t, err, ErrInvalidSemVer)
+
+	v, _ := NewSemverStr("2.5.0")
+	ok, errs := c.Validate(v)
+	assert.False(t, ok)
+	assert.Equal(t, 2, len(errs))
+}
+
+func TestParseCalVerConstraint(t *testing.T) {
+	c, err := ParseCalVerConstraint(">=2023.07 <2024")
+	assert.NoError(t, err)
+
+	v, _ := NewCalVerStr("2023.11.02")
+	assert.True(t, c.Check(v))
+	ok, err := c.CheckString("2024.01")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = ParseCalVerConstraint(">=1.2.3.4")
+	assert.ErrorIs(t, err, ErrInvalidCalVer)
+}
+
+func TestParseConstraintOptions(t *testing.T) {
+	c, err := ParseSemverConstraint(">=1.0.0", WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+	ok, err := c.CheckString("2.0.0-alpha")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "at least 1.0.0, excluding prereleases", c.Describe())
+
+	_, err = ParseSemverConstraint(" ")
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+}
+
+func TestConstraintUntyped(t *testing.T) {
+	c, err := ParseSemverConstraint("^1.2")
+	assert.NoError(t, err)
+	u := c.Constraints()
+	assert.True(t, equivalent(t, u, mustSemverConstraint(t, ">=1.2.0 <2.0.0")))
+	ok, err := u.CheckString("1.5.0")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
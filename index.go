@@ -0,0 +1,173 @@
+package vc
+
+import (
+	"sort"
+	"sync"
+)
+
+// ConstraintIndex finds the constraints, each stored under an id, that a
+// version satisfies. The intervals of all constraints are kept in an
+// interval tree, so a lookup visits only the intervals around the version.
+// It is safe for concurrent use; lookups can run in parallel.
+//
+// The tree is rebuilt by the first lookup after constraints were added or
+// removed, so loading n constraints before looking up costs a single
+// O(n log n) rebuild.
+type ConstraintIndex struct {
+	mu       sync.RWMutex
+	matchers map[string]*Matcher
+	// The intervals of all constraints sorted by lower bound. They form an
+	// implicit balanced tree: the root of a range is its middle element.
+	nodes []indexNode
+	// Whether matchers changed since nodes were built
+	dirty bool
+}
+
+type indexNode struct {
+	interval
+	id string
+	// The highest upper bound of the subtree rooted at this node.
+	maxHi bound
+}
+
+// NewConstraintIndex creates an empty index.
+func NewConstraintIndex() *ConstraintIndex {
+	return &ConstraintIndex{matchers: make(map[string]*Matcher)}
+}
+
+// Add stores constraints under an id, replacing those already stored under
+// it.
+func (x *ConstraintIndex) Add(id string, c *Constraints) {
+	m := c.Compile()
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.matchers[id] = m
+	x.dirty = true
+}
+
+// Remove deletes the constraints stored under an id and reports whether
+// there were any.
+func (x *ConstraintIndex) Remove(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if _, ok := x.matchers[id]; !ok {
+		return false
+	}
+	delete(x.matchers, id)
+	x.dirty = true
+	return true
+}
+
+// Len returns the number of stored constraints.
+func (x *ConstraintIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.matchers)
+}
+
+// Match returns the sorted ids of the constraints the version satisfies.
+func (x *ConstraintIndex) Match(ver Comparable) []string {
+	x.rlock()
+	defer x.mu.RUnlock()
+
+	var ids []string
+	x.search(0, len(x.nodes), ver, func(n *indexNode) {
+		// The intervals ignore the prerelease policy, which the matcher
+		// applies.
+		if x.matchers[n.id].Check(ver) {
+			ids = append(ids, n.id)
+		}
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// rlock read-locks the index, rebuilding the tree first if it is out of
+// date.
+func (x *ConstraintIndex) rlock() {
+	x.mu.RLock()
+	for x.dirty {
+		x.mu.RUnlock()
+		x.mu.Lock()
+		if x.dirty {
+			x.rebuild()
+			x.dirty = false
+		}
+		x.mu.Unlock()
+		x.mu.RLock()
+	}
+}
+
+// rebuild sorts the intervals of all constraints and computes the highest
+// upper bound of each subtree.
+func (x *ConstraintIndex) rebuild() {
+	x.nodes = x.nodes[:0]
+	for id, m := range x.matchers {
+		for _, i := range m.set {
+			x.nodes = append(x.nodes, indexNode{interval: i, id: id})
+		}
+	}
+	sort.Slice(x.nodes, func(i, j int) bool {
+		if d := compareLower(x.nodes[i].lo, x.nodes[j].lo); d != 0 {
+			return d < 0
+		}
+		return x.nodes[i].id < x.nodes[j].id
+	})
+	x.augment(0, len(x.nodes))
+}
+
+func (x *ConstraintIndex) augment(lo, hi int) bound {
+	if lo >= hi {
+		return bound{}
+	}
+	mid := (lo + hi) / 2
+	n := &x.nodes[mid]
+	n.maxHi = n.hi
+	if l := x.augment(lo, mid); mid > lo && compareUpper(l, n.maxHi) > 0 {
+		n.maxHi = l
+	}
+	if r := x.augment(mid+1, hi); mid+1 < hi && compareUpper(r, n.maxHi) > 0 {
+		n.maxHi = r
+	}
+	return n.maxHi
+}
+
+// search calls fn with each interval of the subtree in [lo, hi) that
+// contains the version.
+func (x *ConstraintIndex) search(lo, hi int, ver Comparable, fn func(*indexNode)) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	n := &x.nodes[mid]
+	if aboveUpper(ver, n.maxHi) {
+		return
+	}
+	x.search(lo, mid, ver, fn)
+	if belowLower(ver, n.lo) {
+		// The intervals on the right start at or after this one.
+		return
+	}
+	if !aboveUpper(ver, n.hi) {
+		fn(n)
+	}
+	x.search(mid+1, hi, ver, fn)
+}
+
+// aboveUpper tests if a version is above an upper bound.
+func aboveUpper(ver Comparable, b bound) bool {
+	if b.v == nil {
+		return false
+	}
+	d := Compare(ver, b.v)
+	return d > 0 || (d == 0 && !b.inclusive)
+}
+
+// belowLower tests if a version is below a lower bound.
+func belowLower(ver Comparable, b bound) bool {
+	if b.v == nil {
+		return false
+	}
+	d := Compare(ver, b.v)
+	return d < 0 || (d == 0 && !b.inclusive)
+}
@@ -0,0 +1,128 @@
+package vc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintIndex(t *testing.T) {
+	x := NewConstraintIndex()
+	rules := map[string]string{
+		"CVE-1": "<1.2.5",
+		"CVE-2": ">=1.0.0 <1.4.0 || >=2.0.0 <2.0.3",
+		"CVE-3": "=1.3.0",
+		"gate":  "^2 !=2.0.1",
+		"all":   "*",
+		"none":  "^1 ^3",
+		"open":  ">3",
+	}
+	for id, con := range rules {
+		x.Add(id, mustSemverConstraint(t, con))
+	}
+	assert.Equal(t, len(rules), x.Len())
+
+	tests := []struct {
+		ver      string
+		expected []string
+	}{
+		{"0.5.0", []string{"CVE-1", "all"}},
+		{"1.3.0", []string{"CVE-2", "CVE-3", "all"}},
+		{"1.3.1", []string{"CVE-2", "all"}},
+		{"1.5.0", []string{"all"}},
+		{"2.0.1", []string{"CVE-2", "all"}},
+		{"2.0.2", []string{"CVE-2", "all", "gate"}},
+		{"2.9.0", []string{"all", "gate"}},
+		{"3.0.0", []string{"all"}},
+		{"9.0.0", []string{"all", "open"}},
+	}
+	for _, tc := range tests {
+		v, _ := NewSemverStr(tc.ver)
+		assert.Equal(t, tc.expected, x.Match(v), tc.ver)
+	}
+
+	assert.True(t, x.Remove("all"))
+	assert.False(t, x.Remove("all"))
+	v, _ := NewSemverStr("1.5.0")
+	assert.Nil(t, x.Match(v))
+
+	// Add replaces the constraints of an id.
+	x.Add("CVE-3", mustSemverConstraint(t, "~1.5"))
+	assert.Equal(t, []string{"CVE-3"}, x.Match(v))
+}
+
+func TestConstraintIndexPrerelease(t *testing.T) {
+	x := NewConstraintIndex()
+	npm, err := NewConstraint(">=1.0.0", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+	x.Add("npm", npm)
+	x.Add("default", mustSemverConstraint(t, ">=1.0.0"))
+
+	v, _ := NewSemverStr("2.0.0-alpha")
+	assert.Equal(t, []string{"default"}, x.Match(v))
+}
+
+func TestConstraintIndexAgreesWithCheck(t *testing.T) {
+	x := NewConstraintIndex()
+	cons := make(map[string]*Constraints)
+	for i := 0; i < 60; i++ {
+		id := fmt.Sprintf("r%02d", i)
+		cons[id] = mustSemverConstraint(t, fmt.Sprintf(">=%d.%d <%d || =%d.0.%d", i%7, i%5, i%7+1+i%3, i%4, i%9))
+		x.Add(id, cons[id])
+	}
+
+	var wg sync.WaitGroup
+	for major := 0; major < 10; major++ {
+		wg.Add(1)
+		go func(major int) {
+			defer wg.Done()
+			for minor := 0; minor < 6; minor++ {
+				for patch := 0; patch < 10; patch++ {
+					v := NewSemver(uint64(major), uint64(minor), uint64(patch), "", "")
+					var expected []string
+					for id, c := range cons {
+						if c.Check(v) {
+							expected = append(expected, id)
+						}
+					}
+					assert.ElementsMatch(t, expected, x.Match(v), formatVersion(v))
+				}
+			}
+		}(major)
+	}
+	wg.Wait()
+}
+
+func TestConstraintIndexConcurrentAdd(t *testing.T) {
+	x := NewConstraintIndex()
+	v, _ := NewSemverStr("1.5.0")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				id := fmt.Sprintf("r%d-%02d", i, j)
+				x.Add(id, mustSemverConstraint(t, fmt.Sprintf("^1.%d", j%10)))
+				// Added constraints are found by the next lookup.
+				assert.Equal(t, j%10 <= 5, containsID(x.Match(v), id), id)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 160, x.Len())
+	assert.Equal(t, 96, len(x.Match(v)))
+}
+
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package vc
+
+import "sort"
+
+// bound is one end of an interval. A nil version means the interval is
+// unbounded on that side.
+type bound struct {
+	v         Comparable
+	inclusive bool
+}
+
+// interval is a contiguous range of versions ordered by Compare.
+type interval struct {
+	lo, hi bound
+}
+
+// intervalSet is a union of sorted, disjoint and non-adjacent intervals.
+type intervalSet []interval
+
+// fullSet returns the set containing every version.
+func fullSet() intervalSet {
+	return intervalSet{{}}
+}
+
+// compareLower orders two lower bounds. An unbounded lower end sorts first,
+// and an inclusive bound sorts before an exclusive one on the same version.
+func compareLower(a, b bound) int {
+	if a.v == nil || b.v == nil {
+		return boolCompare(b.v == nil, a.v == nil)
+	}
+	if d := Compare(a.v, b.v); d != 0 {
+		return d
+	}
+	return boolCompare(!a.inclusive, !b.inclusive)
+}
+
+// compareUpper orders two upper bounds. An unbounded upper end sorts last,
+// and an exclusive bound sorts before an inclusive one on the same version.
+func compareUpper(a, b bound) int {
+	if a.v == nil || b.v == nil {
+		return boolCompare(a.v == nil, b.v == nil)
+	}
+	if d := Compare(a.v, b.v); d != 0 {
+		return d
+	}
+	return boolCompare(a.inclusive, b.inclusive)
+}
+
+func boolCompare(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if a {
+		return 1
+	}
+	return -1
+}
+
+// empty reports whether no version lies within the interval.
+func (i interval) empty() bool {
+	if i.lo.v == nil || i.hi.v == nil {
+		return false
+	}
+	d := Compare(i.lo.v, i.hi.v)
+	if d != 0 {
+		return d > 0
+	}
+	return !i.lo.inclusive || !i.hi.inclusive
+}
+
+// point reports whether the interval contains exactly one version.
+func (i interval) point() bool {
+	return i.lo.v != nil && i.hi.v != nil && i.lo.inclusive && i.hi.inclusive &&
+		Compare(i.lo.v, i.hi.v) == 0
+}
+
+func (i interval) contains(ver Comparable) bool {
+	if i.lo.v != nil {
+		d := Compare(ver, i.lo.v)
+		if d < 0 || (d == 0 && !i.lo.inclusive) {
+			return false
+		}
+	}
+	if i.hi.v != nil {
+		d := Compare(ver, i.hi.v)
+		if d > 0 || (d == 0 && !i.hi.inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// touches reports whether the interval b, which does not start before a,
+// overlaps or is adjacent to a, so the two can be merged into one.
+func (i interval) touches(b interval) bool {
+	if i.hi.v == nil || b.lo.v == nil {
+		return true
+	}
+	d := Compare(b.lo.v, i.hi.v)
+	if d != 0 {
+		return d < 0
+	}
+	return b.lo.inclusive || i.hi.inclusive
+}
+
+func (i interval) intersect(b interval) interval {
+	r := i
+	if compareLower(b.lo, r.lo) > 0 {
+		r.lo = b.lo
+	}
+	if compareUpper(b.hi, r.hi) < 0 {
+		r.hi = b.hi
+	}
+	return r
+}
+
+// normalize sorts the intervals and merges those that overlap or touch.
+func normalize(is []interval) intervalSet {
+	sorted := make([]interval, 0, len(is))
+	for _, v := range is {
+		if !v.empty() {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareLower(sorted[i].lo, sorted[j].lo) < 0
+	})
+
+	var result intervalSet
+	for _, v := range sorted {
+		n := len(result)
+		if n > 0 && result[n-1].touches(v) {
+			if compareUpper(v.hi, result[n-1].hi) > 0 {
+				result[n-1].hi = v.hi
+			}
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func (s intervalSet) contains(ver Comparable) bool {
+	for _, v := range s {
+		if v.contains(ver) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s intervalSet) union(o intervalSet) intervalSet {
+	all := make([]interval, 0, len(s)+len(o))
+	all = append(all, s...)
+	all = append(all, o...)
+	return normalize(all)
+}
+
+func (s intervalSet) intersect(o intervalSet) intervalSet {
+	var result []interval
+	for _, a := range s {
+		for _, b := range o {
+			if r := a.intersect(b); !r.empty() {
+				result = append(result, r)
+			}
+		}
+	}
+	return normalize(result)
+}
+
+func (s intervalSet) complement() intervalSet {
+	var result []interval
+	lo := bound{}
+	for _, v := range s {
+		if v.lo.v != nil {
+			result = append(result, interval{lo: lo, hi: bound{v: v.lo.v, inclusive: !v.lo.inclusive}})
+		}
+		if v.hi.v == nil {
+			return normalize(result)
+		}
+		lo = bound{v: v.hi.v, inclusive: !v.hi.inclusive}
+	}
+	result = append(result, interval{lo: lo})
+	return normalize(result)
+}
+
+func (s intervalSet) equal(o intervalSet) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for k := range s {
+		if compareLower(s[k].lo, o[k].lo) != 0 || compareUpper(s[k].hi, o[k].hi) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// atomSet returns the versions matched by a single comparator.
+func atomSet(c *constraint) intervalSet {
+	switch c.operator {
+	case OperatorGte:
+		return intervalSet{{lo: bound{v: c.com, inclusive: true}}}
+	case OperatorGt:
+		return intervalSet{{lo: bound{v: c.com}}}
+	case OperatorLte:
+		return intervalSet{{hi: bound{v: c.com, inclusive: true}}}
+	case OperatorLt:
+		return intervalSet{{hi: bound{v: c.com}}}
+	case OperatorEq, pep440Arbitrary:
+		return intervalSet{{lo: bound{v: c.com, inclusive: true}, hi: bound{v: c.com, inclusive: true}}}
+	default:
+		return intervalSet{
+			{hi: bound{v: c.com}},
+			{lo: bound{v: c.com}},
+		}
+	}
+}
+
+// groupSet returns the versions matched by all comparators of a group.
+func groupSet(group []*constraint) intervalSet {
+	s := fullSet()
+	for _, v := range group {
+		s = s.intersect(atomSet(v))
+	}
+	return s
+}
+
+// set returns the versions matched by the constraints.
+func (c *Constraints) set() intervalSet {
+	var s intervalSet
+	for _, g := range c.constraints {
+		s = s.union(groupSet(g))
+	}
+	return s
+}
+
+// groups converts the set back into comparator groups. Neighbouring
+// intervals separated by a single excluded version are joined into one group
+// with a != comparator.
+func (s intervalSet) groups() [][]*constraint {
+	var result [][]*constraint
+	for k := 0; k < len(s); k++ {
+		v := s[k]
+		if v.point() {
+			result = append(result, []*constraint{newConstraint(OperatorEq, v.lo.v)})
+			continue
+		}
+		var excluded []Comparable
+		for k+1 < len(s) && excludesOne(s[k], s[k+1]) {
+			excluded = append(excluded, s[k].hi.v)
+			k++
+			v.hi = s[k].hi
+		}
+		var group []*constraint
+		if v.lo.v != nil {
+			op := OperatorGt
+			if v.lo.inclusive {
+				op = OperatorGte
+			}
+			group = append(group, newConstraint(op, v.lo.v))
+		}
+		if v.hi.v != nil {
+			op := OperatorLt
+			if v.hi.inclusive {
+				op = OperatorLte
+			}
+			group = append(group, newConstraint(op, v.hi.v))
+		}
+		for _, e := range excluded {
+			group = append(group, newConstraint(OperatorNe, e))
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// excludesOne reports whether exactly one version separates a and b.
+func excludesOne(a, b interval) bool {
+	return a.hi.v != nil && b.lo.v != nil && !a.hi.inclusive && !b.lo.inclusive &&
+		Compare(a.hi.v, b.lo.v) == 0
+}
+
+// newConstraint creates a comparator that was not parsed from an expression.
+func newConstraint(op string, com Comparable) *constraint {
+	c := &constraint{version: formatVersion(com), operator: op, com: com}
+	c.original = c.canonical()
+	return c
+}
@@ -0,0 +1,212 @@
+package vc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiagnosticKind classifies a problem reported by Lint.
+type DiagnosticKind int
+
+const (
+	// DiagnosticUnsatisfiable reports a group that no version can satisfy,
+	// e.g. >2.0.0 <1.0.0.
+	DiagnosticUnsatisfiable DiagnosticKind = iota
+	// DiagnosticSubsumed reports a group whose versions are all matched by
+	// the other groups, e.g. the second group of ^1.2 || ~1.4.
+	DiagnosticSubsumed
+	// DiagnosticRedundant reports a comparator that can be removed without
+	// changing its group, e.g. >=1.0.0 in >=1.0.0 ^1.5.
+	DiagnosticRedundant
+	// DiagnosticNoopExclusion reports a != comparator that excludes a version
+	// the rest of its group rejects anyway, e.g. !=3.0.0 in ^1 !=3.0.0.
+	DiagnosticNoopExclusion
+	// DiagnosticWildcardOperator reports a wildcard version combined with a
+	// comparison operator, e.g. >=1.2.x.
+	DiagnosticWildcardOperator
+)
+
+// String returns a short name of the kind.
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticUnsatisfiable:
+		return "unsatisfiable"
+	case DiagnosticSubsumed:
+		return "subsumed"
+	case DiagnosticRedundant:
+		return "redundant"
+	case DiagnosticNoopExclusion:
+		return "noop-exclusion"
+	case DiagnosticWildcardOperator:
+		return "wildcard-operator"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found in Constraints by Lint.
+type Diagnostic struct {
+	Kind DiagnosticKind
+	// Group is the index of the OR group the problem was found in.
+	Group int
+	// Source is the original text of the offending comparator, or of the
+	// whole group for group level problems.
+	Source  string
+	Message string
+}
+
+// String returns the diagnostic in the form "group 1: <message>".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("group %d: %s", d.Group, d.Message)
+}
+
+// Lint reports unsatisfiable groups, groups subsumed by the other groups,
+// redundant comparators, != comparators that exclude nothing and wildcards
+// mixed with operators. Diagnostics are ordered by group index.
+//
+// Groups and comparators are only reported as subsumed, redundant or
+// excluding nothing for constraints the set operations accept: under
+// PrereleaseNPM a prerelease comparator admits prereleases no range
+// accounts for, e.g. the second group of >=1.0.0 <2.0.0 || >=1.2.3-beta <1.2.4
+// is the only one admitting 1.2.3-beta.2.
+func Lint(c *Constraints) []Diagnostic {
+	var diags []Diagnostic
+	ordered := orderedSets(c) == nil
+	sets := make([]intervalSet, len(c.constraints))
+	for k, g := range c.constraints {
+		sets[k] = groupSet(g)
+		if len(sets[k]) == 0 {
+			src := groupSource(g)
+			diags = append(diags, Diagnostic{
+				Kind:    DiagnosticUnsatisfiable,
+				Group:   k,
+				Source:  src,
+				Message: fmt.Sprintf("%s can never be satisfied", src),
+			})
+			continue
+		}
+		diags = append(diags, lintGroup(k, g, sets[k], ordered)...)
+	}
+	if ordered {
+		diags = append(diags, lintSubsumed(c.constraints, sets)...)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Group < diags[j].Group
+	})
+	return diags
+}
+
+// lintGroup reports the comparators of a group mixing wildcards with
+// operators and, if ordered, those the rest of the group implies.
+func lintGroup(index int, group []*constraint, set intervalSet, ordered bool) []Diagnostic {
+	var diags []Diagnostic
+	ts := terms(group)
+	active := make([]bool, len(ts))
+	for k := range active {
+		active[k] = true
+	}
+	for k, t := range ts {
+		src := t[0].original
+		if op, ver := splitOperator(src); op != "" && op != OperatorCaret &&
+			op != OperatorTilde && op != OperatorEq && hasWildcard(ver) {
+			diags = append(diags, Diagnostic{
+				Kind:    DiagnosticWildcardOperator,
+				Group:   index,
+				Source:  src,
+				Message: fmt.Sprintf("wildcard version combined with operator %q in %s", op, src),
+			})
+		}
+
+		if !ordered {
+			continue
+		}
+		var rest []*constraint
+		for k2, t2 := range ts {
+			if k2 != k && active[k2] {
+				rest = append(rest, t2...)
+			}
+		}
+		if !groupSet(rest).equal(set) {
+			continue
+		}
+		active[k] = false
+		if t[0].canonicalOperator() == OperatorNe {
+			diags = append(diags, Diagnostic{
+				Kind:    DiagnosticNoopExclusion,
+				Group:   index,
+				Source:  src,
+				Message: fmt.Sprintf("%s excludes nothing the rest of the group matches", src),
+			})
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Kind:    DiagnosticRedundant,
+			Group:   index,
+			Source:  src,
+			Message: fmt.Sprintf("%s is implied by the rest of the group", src),
+		})
+	}
+	return diags
+}
+
+// lintSubsumed reports groups matched entirely by the other groups. Groups
+// are visited from last to first, so of two identical groups the later one
+// is reported.
+func lintSubsumed(groups [][]*constraint, sets []intervalSet) []Diagnostic {
+	var diags []Diagnostic
+	subsumed := make([]bool, len(groups))
+	for k := len(groups) - 1; k >= 0; k-- {
+		if len(sets[k]) == 0 {
+			continue
+		}
+		var others intervalSet
+		for k2, s := range sets {
+			if k2 != k && !subsumed[k2] {
+				others = others.union(s)
+			}
+		}
+		if len(sets[k].intersect(others.complement())) > 0 {
+			continue
+		}
+		subsumed[k] = true
+		src := groupSource(groups[k])
+		diags = append(diags, Diagnostic{
+			Kind:    DiagnosticSubsumed,
+			Group:   k,
+			Source:  src,
+			Message: fmt.Sprintf("%s is already matched by the other groups", src),
+		})
+	}
+	return diags
+}
+
+// terms splits a group into the comparators parsed from each source term,
+// e.g. ^1.5 !=1.6.0 gives [>=1.5.0 <2.0.0] and [!=1.6.0].
+func terms(group []*constraint) [][]*constraint {
+	var result [][]*constraint
+	for _, v := range group {
+		n := len(result)
+		if n > 0 {
+			first := result[n-1][0]
+			repeated := first.operator == v.operator && Compare(first.com, v.com) == 0
+			if first.original == v.original && !repeated {
+				result[n-1] = append(result[n-1], v)
+				continue
+			}
+		}
+		result = append(result, []*constraint{v})
+	}
+	return result
+}
+
+// groupSource returns the original text of the terms of a group.
+func groupSource(group []*constraint) string {
+	ts := terms(group)
+	srcs := make([]string, 0, len(ts))
+	for _, t := range ts {
+		srcs = append(srcs, t[0].original)
+	}
+	return strings.Join(srcs, " ")
+}
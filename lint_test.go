@@ -0,0 +1,104 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	type diag struct {
+		kind   DiagnosticKind
+		group  int
+		source string
+	}
+	tests := []struct {
+		con      string
+		expected []diag
+	}{
+		{"^1.2 || ^2", nil},
+		{">=1.1 <2 !=1.2.3", nil},
+		{">2.0.0 <1.0.0", []diag{
+			{DiagnosticUnsatisfiable, 0, ">2.0.0 <1.0.0"},
+		}},
+		{"^1.2 || ~1.4", []diag{
+			{DiagnosticSubsumed, 1, "~1.4"},
+		}},
+		{"^1 || ^2 || >=1.5.0 <2.5.0", []diag{
+			{DiagnosticSubsumed, 2, ">=1.5.0 <2.5.0"},
+		}},
+		{"^1 || ^1", []diag{
+			{DiagnosticSubsumed, 1, "^1"},
+		}},
+		{">=1.0.0 >=1.2.0 <3.0.0 || ^1.5", []diag{
+			{DiagnosticRedundant, 0, ">=1.0.0"},
+			{DiagnosticSubsumed, 1, "^1.5"},
+		}},
+		{"^1.5 ^1.6", []diag{
+			{DiagnosticRedundant, 0, "^1.5"},
+		}},
+		{"^1 ^1", []diag{
+			{DiagnosticRedundant, 0, "^1"},
+		}},
+		{"^1 !=3.0.0", []diag{
+			{DiagnosticNoopExclusion, 0, "!=3.0.0"},
+		}},
+		{"^1 !1.2.0 !=1.2.0", []diag{
+			{DiagnosticNoopExclusion, 0, "!1.2.0"},
+		}},
+		{">=1.2.x", []diag{
+			{DiagnosticWildcardOperator, 0, ">=1.2.x"},
+		}},
+		{"1.2.x || ~2.x || ^3.x", nil},
+		{">2.0.0 <1.0.0 || ^1 !=3.0.0 || ~1.2", []diag{
+			{DiagnosticUnsatisfiable, 0, ">2.0.0 <1.0.0"},
+			{DiagnosticNoopExclusion, 1, "!=3.0.0"},
+			{DiagnosticSubsumed, 2, "~1.2"},
+		}},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		got := Lint(c)
+		assert.Equal(t, len(tc.expected), len(got), tc.con)
+		for k, d := range got {
+			if k >= len(tc.expected) {
+				break
+			}
+			assert.Equal(t, tc.expected[k].kind, d.Kind, tc.con)
+			assert.Equal(t, tc.expected[k].group, d.Group, tc.con)
+			assert.Equal(t, tc.expected[k].source, d.Source, tc.con)
+			assert.NotEmpty(t, d.Message)
+		}
+	}
+}
+
+func TestLintPrereleasePolicy(t *testing.T) {
+	newfn := func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}
+	// Only the second group admits 1.2.3-beta.2 under the npm policy.
+	c, err := NewConstraint(">=1.0.0 <2.0.0 || >=1.2.3-beta <1.2.4", newfn, WithDialect(DialectNPM))
+	assert.NoError(t, err)
+	ok, _ := c.CheckString("1.2.3-beta.2")
+	assert.True(t, ok)
+	assert.Empty(t, Lint(c))
+
+	// Without prerelease comparators, the npm policy matches ranges of
+	// releases.
+	c, err = NewConstraint("^1.2 || ~1.4 || >2.0.0 <1.0.0", newfn, WithDialect(DialectNPM))
+	assert.NoError(t, err)
+	got := Lint(c)
+	if assert.Equal(t, 2, len(got)) {
+		assert.Equal(t, DiagnosticSubsumed, got[0].Kind)
+		assert.Equal(t, DiagnosticUnsatisfiable, got[1].Kind)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	c := mustSemverConstraint(t, "^1.2 || ~1.4")
+	diags := Lint(c)
+	assert.Equal(t, 1, len(diags))
+	assert.Equal(t, "group 1: ~1.4 is already matched by the other groups", diags[0].String())
+	assert.Equal(t, "subsumed", diags[0].Kind.String())
+}
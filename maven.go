@@ -0,0 +1,527 @@
+package vc
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var _ Ordered = &MavenVersion{}
+
+// MavenVersion is a version of a Maven artifact, e.g. 1.0-SNAPSHOT or
+// 2.0.0.Final. Versions are ordered like Maven's ComparableVersion orders
+// them: the version is split into items at ".", "-" and transitions between
+// digits and letters, and qualifiers are ranked
+//
+//	alpha < beta < milestone < rc = cr < snapshot < "" = final = ga = release < sp
+//
+// with unknown qualifiers after sp, in lexical order. a1, b1 and m1 are
+// short for alpha-1, beta-1 and milestone-1.
+type MavenVersion struct {
+	items   *mavenItem
+	release []uint64
+	// The text after the release numbers, e.g. SNAPSHOT of 1.0-SNAPSHOT
+	qualifier string
+	original  string
+}
+
+// NewMavenVersionStr parses a Maven version and returns an instance of
+// MavenVersion or an error if unable to parse the version. Maven accepts
+// almost any text as a version, only empty versions and versions with
+// spaces, commas, brackets or parentheses, which would be ambiguous in
+// ranges, are rejected with a *ParseError wrapping ErrInvalidMaven.
+func NewMavenVersionStr(ver string) (*MavenVersion, error) {
+	if ver == "" {
+		return nil, &ParseError{Input: ver, Reason: "empty version", Err: ErrInvalidMaven}
+	}
+	if i := strings.IndexFunc(ver, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",[]()", r)
+	}); i >= 0 {
+		return nil, &ParseError{Input: ver, Offset: i, Token: ver[i:], Reason: "unexpected character", Err: ErrInvalidMaven}
+	}
+
+	v := &MavenVersion{items: parseMavenItems(ver), original: ver}
+	rest := ver
+	for {
+		n := len(rest) - len(strings.TrimLeft(rest, allowedNum))
+		if n == 0 {
+			break
+		}
+		num, err := strconv.ParseUint(rest[:n], 10, 64)
+		if err != nil {
+			return nil, &ParseError{Input: ver, Offset: len(ver) - len(rest), Token: rest[:n],
+				Reason: "number out of range", Err: ErrInvalidMaven}
+		}
+		v.release = append(v.release, num)
+		rest = rest[n:]
+		if !strings.HasPrefix(rest, ".") {
+			break
+		}
+		rest = rest[1:]
+	}
+	v.qualifier = strings.TrimLeft(rest, ".-")
+	return v, nil
+}
+
+// String returns the version as it was given.
+func (v *MavenVersion) String() string {
+	return v.original
+}
+
+// Original returns the original value passed in to be parsed.
+func (v *MavenVersion) Original() string {
+	return v.original
+}
+
+// Version returns the leading release numbers, e.g. 1.0.0 of 1.0.0.Final.
+func (v *MavenVersion) Version() string {
+	parts := make([]string, 0, len(v.release))
+	for _, n := range v.release {
+		parts = append(parts, strconv.FormatUint(n, 10))
+	}
+	return strings.Join(parts, ".")
+}
+
+// Qualifier returns the text after the release numbers, e.g. SNAPSHOT of
+// 1.0-SNAPSHOT.
+func (v *MavenVersion) Qualifier() string {
+	return v.qualifier
+}
+
+// Major returns the first release number.
+func (v *MavenVersion) Major() uint64 {
+	return v.part(0)
+}
+
+// Minor returns the second release number, 0 if there is none.
+func (v *MavenVersion) Minor() uint64 {
+	return v.part(1)
+}
+
+// Patch returns the third release number, 0 if there is none.
+func (v *MavenVersion) Patch() uint64 {
+	return v.part(2)
+}
+
+func (v *MavenVersion) part(i int) uint64 {
+	if i < len(v.release) {
+		return v.release[i]
+	}
+	return 0
+}
+
+// Prerelease returns the qualifier if it sorts before the release, e.g.
+// SNAPSHOT of 1.0-SNAPSHOT or RC1 of 2.0-RC1. The qualifiers of final
+// releases, service packs and unknown qualifiers are not prereleases.
+func (v *MavenVersion) Prerelease() string {
+	if v.qualifier == "" || len(v.release) == 0 {
+		return ""
+	}
+	if compareMavenItems(v.items, parseMavenItems(v.Version())) >= 0 {
+		return ""
+	}
+	return v.qualifier
+}
+
+// IncMajor produces the next major version, e.g. 2.0.0 after 1.4-SNAPSHOT.
+func (v *MavenVersion) IncMajor() Comparable {
+	return newMavenRelease(v.Major()+1, 0, 0)
+}
+
+// IncMinor produces the next minor version, e.g. 1.5.0 after 1.4-SNAPSHOT.
+func (v *MavenVersion) IncMinor() Comparable {
+	return newMavenRelease(v.Major(), v.Minor()+1, 0)
+}
+
+// IncPatch produces the next patch version. A prerelease produces its
+// release, e.g. 1.4.0 after 1.4-SNAPSHOT.
+func (v *MavenVersion) IncPatch() Comparable {
+	if v.Prerelease() != "" {
+		return newMavenRelease(v.Major(), v.Minor(), v.Patch())
+	}
+	return newMavenRelease(v.Major(), v.Minor(), v.Patch()+1)
+}
+
+func newMavenRelease(major, minor, patch uint64) *MavenVersion {
+	v, _ := NewMavenVersionStr(strconv.FormatUint(major, 10) + "." +
+		strconv.FormatUint(minor, 10) + "." + strconv.FormatUint(patch, 10))
+	return v
+}
+
+// CompareTo compares the version to another one in the order of Maven.
+// Versions of other types are compared by their major, minor, patch and
+// prerelease parts.
+func (v *MavenVersion) CompareTo(other Comparable) int {
+	o, ok := other.(*MavenVersion)
+	if !ok {
+		return compareParts(v, other)
+	}
+	return compareMavenItems(v.items, o.items)
+}
+
+type mavenKind int
+
+const (
+	mavenInt mavenKind = iota
+	mavenString
+	mavenList
+)
+
+// mavenItem is an item of a parsed Maven version: a number, a qualifier or
+// a list of items that followed a "-" or a transition between digits and
+// letters.
+type mavenItem struct {
+	kind mavenKind
+	// The number without leading zeros, or the qualifier
+	value string
+	items []*mavenItem
+}
+
+// Ranks of the known qualifiers, unknown ones rank after sp.
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2,
+	"rc":        3,
+	"snapshot":  4,
+	"":          5,
+	"sp":        6,
+}
+
+var mavenAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// parseMavenItems splits a version into items the way ComparableVersion
+// does.
+func parseMavenItems(ver string) *mavenItem {
+	ver = strings.ToLower(ver)
+	root := &mavenItem{kind: mavenList}
+	list := root
+	stack := []*mavenItem{root}
+	isDigit := false
+	start := 0
+
+	sub := func() {
+		next := &mavenItem{kind: mavenList}
+		list.items = append(list.items, next)
+		list = next
+		stack = append(stack, next)
+	}
+	for i := 0; i < len(ver); i++ {
+		c := ver[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				list.items = append(list.items, &mavenItem{kind: mavenInt, value: "0"})
+			} else {
+				list.items = append(list.items, newMavenItem(ver[start:i], isDigit, false))
+			}
+			start = i + 1
+			if c == '-' {
+				sub()
+			}
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				list.items = append(list.items, newMavenItem(ver[start:i], false, true))
+				start = i
+				sub()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				list.items = append(list.items, newMavenItem(ver[start:i], true, false))
+				start = i
+				sub()
+			}
+			isDigit = false
+		}
+	}
+	if len(ver) > start {
+		list.items = append(list.items, newMavenItem(ver[start:], isDigit, false))
+	}
+	for k := len(stack) - 1; k >= 0; k-- {
+		stack[k].normalize()
+	}
+	return root
+}
+
+func newMavenItem(s string, isDigit, followedByDigit bool) *mavenItem {
+	if isDigit {
+		s = strings.TrimLeft(s, "0")
+		if s == "" {
+			s = "0"
+		}
+		return &mavenItem{kind: mavenInt, value: s}
+	}
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[s]; ok {
+		s = alias
+	}
+	return &mavenItem{kind: mavenString, value: s}
+}
+
+// isNull tests if the item equals a missing one: 0, the release qualifier
+// or an empty list.
+func (i *mavenItem) isNull() bool {
+	switch i.kind {
+	case mavenInt:
+		return i.value == "0"
+	case mavenString:
+		return i.value == ""
+	default:
+		return len(i.items) == 0
+	}
+}
+
+// normalize removes the null items at the end of the list, e.g. the
+// trailing zeros of 1.0.0, skipping over nested lists.
+func (i *mavenItem) normalize() {
+	for k := len(i.items) - 1; k >= 0; k-- {
+		last := i.items[k]
+		if last.isNull() {
+			i.items = append(i.items[:k], i.items[k+1:]...)
+		} else if last.kind != mavenList {
+			break
+		}
+	}
+}
+
+// compareMavenItems compares two items, either of which may be nil for a
+// missing item.
+func compareMavenItems(a, b *mavenItem) int {
+	if a == nil {
+		if b == nil {
+			return 0
+		}
+		return -compareMavenItems(b, nil)
+	}
+	switch a.kind {
+	case mavenInt:
+		switch {
+		case b == nil:
+			return boolCompare(a.value != "0", false)
+		case b.kind == mavenInt:
+			return compareNumbers(a.value, b.value)
+		default:
+			return 1
+		}
+	case mavenString:
+		switch {
+		case b == nil:
+			return compareQualifiers(a.value, "")
+		case b.kind == mavenString:
+			return compareQualifiers(a.value, b.value)
+		default:
+			return -1
+		}
+	default:
+		switch {
+		case b == nil:
+			if len(a.items) == 0 {
+				return 0
+			}
+			return compareMavenItems(a.items[0], nil)
+		case b.kind == mavenInt:
+			return -1
+		case b.kind == mavenString:
+			return 1
+		}
+		for k := 0; k < len(a.items) || k < len(b.items); k++ {
+			var l, r *mavenItem
+			if k < len(a.items) {
+				l = a.items[k]
+			}
+			if k < len(b.items) {
+				r = b.items[k]
+			}
+			if d := compareMavenItems(l, r); d != 0 {
+				return d
+			}
+		}
+		return 0
+	}
+}
+
+// compareNumbers compares two numbers of any length without leading zeros.
+func compareNumbers(a, b string) int {
+	if len(a) != len(b) {
+		return boolCompare(len(a) > len(b), len(b) > len(a))
+	}
+	return strings.Compare(a, b)
+}
+
+// compareQualifiers compares qualifiers by their rank, unknown qualifiers
+// come after the known ones in lexical order.
+func compareQualifiers(a, b string) int {
+	ra, aok := mavenQualifiers[a]
+	rb, bok := mavenQualifiers[b]
+	switch {
+	case aok && bok:
+		return boolCompare(ra > rb, rb > ra)
+	case aok || bok:
+		return boolCompare(bok, aok)
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// NewMavenRange parses a Maven version range into Constraints whose
+// versions are MavenVersion, e.g.
+//
+//	[1.0,2.0)          -->  >=1.0 <2.0
+//	(,1.5]             -->  <=1.5
+//	[1.2]              -->  =1.2
+//	(,1.0],[1.2,)      -->  <=1.0 || >=1.2
+//
+// Ranges separated by commas are joined with OR and must not overlap. As in
+// Maven, a bare version such as 1.0 is a soft requirement, which only
+// recommends the version and matches any version.
+func NewMavenRange(spec string, opts ...Option) (*Constraints, error) {
+	o := newOptions(opts)
+	var groups [][]*constraint
+	// The upper bound of the previous range, nil if it had none
+	var upper *MavenVersion
+	i := skipSpaces(spec, 0)
+	for i < len(spec) && (spec[i] == '[' || spec[i] == '(') {
+		end := strings.IndexAny(spec[i:], ")]")
+		if end < 0 {
+			return nil, &ParseError{Input: spec, Offset: i, Token: spec[i:], Reason: "missing closing bracket",
+				Err: ErrInvalidConstraint}
+		}
+		text := spec[i : i+end+1]
+		group, lo, hi, err := parseMavenRestriction(text)
+		if err != nil {
+			return nil, relocate(err, spec, i, text)
+		}
+		if upper != nil && (lo == nil || Compare(lo, upper) < 0) {
+			return nil, &ParseError{Input: spec, Offset: i, Token: text, Reason: "ranges overlap",
+				Err: ErrInvalidConstraint}
+		}
+		upper = hi
+		groups = append(groups, group)
+		i = skipSpaces(spec, i+end+1)
+		if i < len(spec) && spec[i] == ',' {
+			i = skipSpaces(spec, i+1)
+		}
+	}
+	if i < len(spec) {
+		rest := strings.TrimSpace(spec[i:])
+		if len(groups) > 0 {
+			return nil, &ParseError{Input: spec, Offset: i, Token: rest, Reason: "expected a range",
+				Err: ErrInvalidConstraint}
+		}
+		if _, err := NewMavenVersionStr(rest); err != nil {
+			return nil, relocate(err, spec, i, rest)
+		}
+		groups = [][]*constraint{{}}
+	}
+	if len(groups) == 0 {
+		return nil, &ParseError{Input: spec, Reason: "empty range", Err: ErrInvalidConstraint}
+	}
+	return &Constraints{
+		constraints: groups,
+		newfn: func(s string) (Comparable, error) {
+			return NewMavenVersionStr(s)
+		},
+		original: spec,
+		policy:   o.prerelease,
+		opts:     opts,
+		syntax:   "Maven range",
+	}, nil
+}
+
+// parseMavenRestriction parses a single bracketed range into its
+// comparators and bounds, nil for a missing bound.
+func parseMavenRestriction(text string) ([]*constraint, *MavenVersion, *MavenVersion, error) {
+	fail := func(offset int, token, reason string) error {
+		return &ParseError{Input: text, Offset: offset, Token: token, Reason: reason, Err: ErrInvalidConstraint}
+	}
+	parse := func(offset, end int) (*MavenVersion, error) {
+		offset = skipSpaces(text, offset)
+		ver := strings.TrimSpace(text[offset:end])
+		if ver == "" {
+			return nil, nil
+		}
+		v, err := NewMavenVersionStr(ver)
+		if err != nil {
+			return nil, relocate(err, text, offset, ver)
+		}
+		return v, nil
+	}
+	atom := func(op string, v *MavenVersion) *constraint {
+		return &constraint{original: text, version: v.String(), operator: op, com: v}
+	}
+	lowerInclusive, upperInclusive := text[0] == '[', text[len(text)-1] == ']'
+
+	comma := strings.IndexByte(text, ',')
+	if comma < 0 {
+		if !lowerInclusive || !upperInclusive {
+			return nil, nil, nil, fail(0, text, "a single version must be enclosed in [ ]")
+		}
+		v, err := parse(1, len(text)-1)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if v == nil {
+			return nil, nil, nil, fail(0, text, "missing version")
+		}
+		return []*constraint{atom(OperatorEq, v)}, v, v, nil
+	}
+	if extra := strings.IndexByte(text[comma+1:], ','); extra >= 0 {
+		return nil, nil, nil, fail(comma+1+extra, ",", "a range has at most two versions")
+	}
+	lo, err := parse(1, comma)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hi, err := parse(comma+1, len(text)-1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if lo != nil && hi != nil {
+		switch d := Compare(lo, hi); {
+		case d > 0:
+			return nil, nil, nil, fail(0, text, "the lower bound is above the upper bound")
+		case d == 0 && (!lowerInclusive || !upperInclusive):
+			return nil, nil, nil, fail(0, text, "the bounds of an open range cannot be equal")
+		}
+	}
+	var group []*constraint
+	if lo != nil {
+		op := OperatorGt
+		if lowerInclusive {
+			op = OperatorGte
+		}
+		group = append(group, atom(op, lo))
+	}
+	if hi != nil {
+		op := OperatorLt
+		if upperInclusive {
+			op = OperatorLte
+		}
+		group = append(group, atom(op, hi))
+	}
+	return group, lo, hi, nil
+}
+
+// skipSpaces returns the offset of the first character at or after i that
+// is not a space.
+func skipSpaces(s string, i int) int {
+	for i < len(s) && unicode.IsSpace(rune(s[i])) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,242 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMavenVersionStr(t *testing.T) {
+	tests := []struct {
+		version   string
+		release   string
+		qualifier string
+		pre       string
+		err       bool
+	}{
+		{"1.0", "1.0", "", "", false},
+		{"1.0-SNAPSHOT", "1.0", "SNAPSHOT", "SNAPSHOT", false},
+		{"1.0.0.Final", "1.0.0", "Final", "", false},
+		{"2.0-RC1", "2.0", "RC1", "RC1", false},
+		{"2.0.RC1", "2.0", "RC1", "RC1", false},
+		{"1.2a1", "1.2", "a1", "a1", false},
+		{"1.0-sp1", "1.0", "sp1", "", false},
+		{"1.0-1", "1.0", "1", "", false},
+		{"1.0-jre", "1.0", "jre", "", false},
+		{"alpha", "", "alpha", "", false},
+		{"", "", "", "", true},
+		{"1.0 beta", "", "", "", true},
+		{"[1.0]", "", "", "", true},
+		{"1,0", "", "", "", true},
+		{"99999999999999999999.0", "", "", "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewMavenVersionStr(tc.version)
+		if tc.err {
+			assert.True(t, errors.Is(err, ErrInvalidMaven), tc.version)
+			continue
+		}
+		if assert.NoError(t, err, tc.version) {
+			assert.Equal(t, tc.release, v.Version(), tc.version)
+			assert.Equal(t, tc.qualifier, v.Qualifier(), tc.version)
+			assert.Equal(t, tc.pre, v.Prerelease(), tc.version)
+			assert.Equal(t, tc.version, v.String())
+		}
+	}
+}
+
+func TestMavenVersionParts(t *testing.T) {
+	v, err := NewMavenVersionStr("3.8.6.1-beta-2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), v.Major())
+	assert.Equal(t, uint64(8), v.Minor())
+	assert.Equal(t, uint64(6), v.Patch())
+
+	assert.Equal(t, "4.0.0", formatVersion(v.IncMajor()))
+	assert.Equal(t, "3.9.0", formatVersion(v.IncMinor()))
+	assert.Equal(t, "3.8.6", formatVersion(v.IncPatch()))
+
+	v, err = NewMavenVersionStr("3.8")
+	assert.NoError(t, err)
+	assert.Equal(t, "3.8.1", formatVersion(v.IncPatch()))
+}
+
+// The versions are in ascending order, as in the ComparableVersion tests of
+// Maven.
+func TestMavenVersionCompare(t *testing.T) {
+	lists := [][]string{
+		{
+			"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2", "1-beta123", "1-m2", "1-m11", "1-rc",
+			"1-cr2", "1-rc123", "1-SNAPSHOT", "1", "1-sp", "1-sp2", "1-sp123", "1-abc", "1-def", "1-pom-1",
+			"1-1-snapshot", "1-1", "1-2", "1-123",
+		},
+		{
+			"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0", "2.1-a", "2.1b", "2.1-c", "2.1-1",
+			"2.1.0.1", "2.2", "2.123", "11.a2", "11.a11", "11.b2", "11.b11", "11.m2", "11.m11", "11", "11.a",
+			"11b", "11c", "11m",
+		},
+	}
+
+	for _, versions := range lists {
+		for i := range versions {
+			for j := range versions {
+				v1, err := NewMavenVersionStr(versions[i])
+				assert.NoError(t, err)
+				v2, err := NewMavenVersionStr(versions[j])
+				assert.NoError(t, err)
+				expected := boolCompare(i > j, j > i)
+				assert.Equal(t, expected, Compare(v1, v2), "%s <=> %s", versions[i], versions[j])
+			}
+		}
+	}
+
+	equal := [][2]string{
+		{"1", "1"},
+		{"1", "1.0"},
+		{"1", "1.0.0"},
+		{"1.0", "1.0.0"},
+		{"1", "1-0"},
+		{"1", "1.0-0"},
+		{"1.0", "1.0-0"},
+		{"1a", "1-a"},
+		{"1a", "1.0-a"},
+		{"1a", "1.0.0-a"},
+		{"1.0a", "1-a"},
+		{"1.0.0a", "1-a"},
+		{"1x", "1-x"},
+		{"1x", "1.0-x"},
+		{"1x", "1.0.0-x"},
+		{"1.0x", "1-x"},
+		{"1.0.0x", "1-x"},
+		{"1ga", "1"},
+		{"1release", "1"},
+		{"1final", "1"},
+		{"1.0.0.Final", "1"},
+		{"1cr", "1rc"},
+		{"1a1", "1-alpha-1"},
+		{"1b2", "1-beta-2"},
+		{"1m3", "1-milestone-3"},
+		{"1X", "1x"},
+		{"1A", "1a"},
+		{"1B", "1b"},
+		{"1M", "1m"},
+		{"1Ga", "1"},
+		{"1GA", "1"},
+		{"1RELEASE", "1"},
+		{"1Final", "1"},
+		{"1FINAL", "1"},
+		{"1Cr", "1Rc"},
+		{"1cR", "1rC"},
+		{"1m3", "1Milestone3"},
+		{"1m3", "1MileStone3"},
+		{"1m3", "1MILESTONE3"},
+		{"1-SNAPSHOT", "1-snapshot"},
+		{"00001", "1"},
+	}
+	for _, tc := range equal {
+		v1, err := NewMavenVersionStr(tc[0])
+		assert.NoError(t, err)
+		v2, err := NewMavenVersionStr(tc[1])
+		assert.NoError(t, err)
+		assert.Equal(t, 0, Compare(v1, v2), "%s <=> %s", tc[0], tc[1])
+	}
+}
+
+func TestNewMavenRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		version  string
+		expected bool
+	}{
+		{"[1.0,2.0)", "1.0", true},
+		{"[1.0,2.0)", "1.5-SNAPSHOT", true},
+		{"[1.0,2.0)", "2.0-SNAPSHOT", true},
+		{"[1.0,2.0)", "2.0", false},
+		{"[1.0,2.0)", "0.9", false},
+		{"(1.0,2.0]", "1.0", false},
+		{"(1.0,2.0]", "2.0.0.Final", true},
+		{"(,1.5]", "1.5", true},
+		{"(,1.5]", "1.5-sp1", false},
+		{"(,1.5]", "0.1", true},
+		{"[1.5,)", "99", true},
+		{"[1.5,)", "1.5-rc1", false},
+		{"[1.2]", "1.2", true},
+		{"[1.2]", "1.2.0", true},
+		{"[1.2]", "1.2.1", false},
+		{"(,1.0],[1.2,)", "1.1", false},
+		{"(,1.0],[1.2,)", "1.0", true},
+		{"(,1.0],[1.2,)", "1.3", true},
+		{"(,1.1),(1.1,)", "1.1", false},
+		{"(,1.1),(1.1,)", "1.1.1", true},
+		{" [ 1.0 , 2.0 ) , [3.0,) ", "3.1", true},
+		{"(,)", "1.0", true},
+		{"1.0", "3.0", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewMavenRange(tc.spec)
+		if !assert.NoError(t, err, tc.spec) {
+			continue
+		}
+		ok, err := c.CheckString(tc.version)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, ok, "%s %s", tc.spec, tc.version)
+	}
+}
+
+func TestMavenRangeCanonical(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"[1.0,2.0)", ">=1.0 <2.0"},
+		{"(1.0,2.0]", ">1.0 <=2.0"},
+		{"(,1.5]", "<=1.5"},
+		{"[1.2]", "1.2"},
+		{"(,1.0],[1.2,)", "<=1.0 || >=1.2"},
+		{"1.0", "*"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewMavenRange(tc.spec)
+		if assert.NoError(t, err, tc.spec) {
+			assert.Equal(t, tc.expected, c.Canonical())
+			assert.Equal(t, tc.spec, c.String())
+		}
+	}
+}
+
+func TestNewMavenRangeError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		offset int
+		token  string
+		err    error
+	}{
+		{"", 0, "", ErrInvalidConstraint},
+		{"[1.0,2.0", 0, "[1.0,2.0", ErrInvalidConstraint},
+		{"(1.0)", 0, "(1.0)", ErrInvalidConstraint},
+		{"[]", 0, "[]", ErrInvalidConstraint},
+		{"[1.0,2.0,3.0]", 8, ",", ErrInvalidConstraint},
+		{"[2.0,1.0]", 0, "[2.0,1.0]", ErrInvalidConstraint},
+		{"[1.0,1.0)", 0, "[1.0,1.0)", ErrInvalidConstraint},
+		{"[1.0,2.0),[1.5,3.0)", 10, "[1.5,3.0)", ErrInvalidConstraint},
+		{"[1.0,2.0),(,3.0)", 10, "(,3.0)", ErrInvalidConstraint},
+		{"[1.0,2.0),1.5", 10, "1.5", ErrInvalidConstraint},
+		{"[1.0, 2 0)", 7, " 0", ErrInvalidMaven},
+		{"1.0 beta", 3, " beta", ErrInvalidMaven},
+	}
+
+	for _, tc := range tests {
+		_, err := NewMavenRange(tc.spec)
+		var pe *ParseError
+		if assert.True(t, errors.As(err, &pe), tc.spec) {
+			assert.Equal(t, tc.spec, pe.Input)
+			assert.Equal(t, tc.offset, pe.Offset, tc.spec)
+			assert.Equal(t, tc.token, pe.Token, tc.spec)
+			assert.True(t, errors.Is(err, tc.err), tc.spec)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package vc
+
+// Option configures how NewConstraint parses and checks constraints.
+type Option func(*options)
+
+type options struct {
+	prerelease PrereleasePolicy
+	// Whether the prerelease policy was given, rather than implied by the
+	// dialect
+	prereleaseSet bool
+	resolve       AliasResolver
+	dialect       Dialect
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !o.prereleaseSet {
+		o.prerelease = o.dialect.prereleasePolicy()
+	}
+	return o
+}
+
+// PrereleasePolicy decides which prerelease versions can satisfy
+// constraints.
+type PrereleasePolicy int
+
+const (
+	// PrereleaseIncludeAll matches prereleases like any other version, by
+	// the order of Compare. >=1.0.0 accepts 2.0.0-alpha and ^1.2.3 accepts
+	// 1.5.0-beta. This is the default.
+	PrereleaseIncludeAll PrereleasePolicy = iota
+	// PrereleaseNPM follows node-semver: a prerelease satisfies a group only
+	// if some comparator of the group has a prerelease on the same
+	// major.minor.patch tuple. >=1.0.0 rejects 2.0.0-alpha, while
+	// >=1.2.3-beta.2 accepts 1.2.3-beta.4 but not 1.2.4-beta.1.
+	PrereleaseNPM
+	// PrereleaseExclude never matches prereleases. >=1.0.0a1 rejects
+	// 1.0.0rc1 as well as 2.0.0-alpha.
+	PrereleaseExclude
+)
+
+// WithPrereleasePolicy sets the policy deciding which prerelease versions
+// satisfy the constraints.
+func WithPrereleasePolicy(p PrereleasePolicy) Option {
+	return func(o *options) {
+		o.prerelease = p
+		o.prereleaseSet = true
+	}
+}
+
+// admitsPrerelease tests if the prerelease policy lets a version satisfy a
+// group whose comparators it already passes.
+func (p PrereleasePolicy) admitsPrerelease(ver Comparable, group []*constraint) bool {
+	if p == PrereleaseIncludeAll || ver.Prerelease() == "" {
+		return true
+	}
+	if p == PrereleaseExclude {
+		return false
+	}
+	for _, v := range group {
+		if v.com.Prerelease() != "" && sameRelease(v.com, ver) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRelease tests if two versions have the same major, minor and patch.
+func sameRelease(v1, v2 Comparable) bool {
+	return v1.Major() == v2.Major() && v1.Minor() == v2.Minor() && v1.Patch() == v2.Patch()
+}
@@ -0,0 +1,87 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrereleasePolicy(t *testing.T) {
+	tests := []struct {
+		con string
+		ver string
+		all bool
+		npm bool
+	}{
+		{">=1.0.0", "2.0.0-alpha", true, false},
+		{">=1.0.0", "2.0.0", true, true},
+		{"<2.0.0", "2.0.0-alpha", true, false},
+		{"*", "1.2.3-alpha", true, false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true, true},
+		{"^1.2.3-beta.2", "1.2.3-beta.1", false, false},
+		{"^1.2.3-beta.2", "1.2.4-beta.2", true, false},
+		{"^1.2.3-beta.2", "1.2.4", true, true},
+		{">1.2.3-alpha.3", "1.2.3-alpha.7", true, true},
+		{">1.2.3-alpha.3", "3.4.5-alpha.9", true, false},
+		{">=1.0.0 <=1.2.3-rc.1", "1.2.3-beta", true, true},
+		{"1.0.0 - 2.0.0-rc.1", "2.0.0-beta", true, true},
+		{"^1 || >=2.0.0-rc.1 <3", "2.0.0-rc.2", true, true},
+		{"^1 || >=2.0.0-rc.1 <3", "1.5.0-rc.2", true, false},
+		{"~1.2.3-alpha || 1.2.4-beta", "1.2.4-beta", true, true},
+	}
+
+	for _, tc := range tests {
+		ver, err := NewSemverStr(tc.ver)
+		assert.NoError(t, err)
+		for _, p := range []struct {
+			policy   PrereleasePolicy
+			expected bool
+		}{{PrereleaseIncludeAll, tc.all}, {PrereleaseNPM, tc.npm}, {PrereleaseExclude, tc.all && ver.Prerelease() == ""}} {
+			c, err := NewConstraint(tc.con, func(s string) (Comparable, error) {
+				return NewSemverStr(s)
+			}, WithPrereleasePolicy(p.policy))
+			assert.NoError(t, err)
+			assert.Equal(t, p.expected, c.Check(ver), "%s %s (policy %d)", tc.con, tc.ver, p.policy)
+			ok, errs := c.Validate(ver)
+			assert.Equal(t, p.expected, ok)
+			if ok {
+				assert.Empty(t, errs)
+			}
+		}
+	}
+}
+
+func TestPrereleasePolicyDefault(t *testing.T) {
+	c := mustSemverConstraint(t, ">=1.0.0")
+	ok, err := c.CheckString("2.0.0-alpha")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPrereleasePolicyValidate(t *testing.T) {
+	c, err := NewConstraint(">=1.0.0 || ^1.2.3-beta.2", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+
+	v, _ := NewSemverStr("2.0.0-alpha")
+	ok, errs := c.Validate(v)
+	assert.False(t, ok)
+	assert.Equal(t, 2, len(errs))
+	assert.EqualError(t, errs[0], "2.0.0-alpha is a prerelease and no comparator of >=1.0.0 is a prerelease of 2.0.0")
+	assert.EqualError(t, errs[1], "2.0.0-alpha is a prerelease and no comparator of ^1.2.3-beta.2 is a prerelease of 2.0.0")
+}
+
+func TestPrereleasePolicyDescribe(t *testing.T) {
+	c, err := NewConstraint(">=1.0.0 || ^1.2.3-beta.2", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+	assert.Equal(t, "at least 1.0.0, excluding prereleases, or "+
+		"at least 1.2.3-beta.2 and below 2.0.0, with prereleases of 1.2.3 only", c.Describe())
+
+	// Simplify keeps the groups, which decide the prereleases admitted.
+	ok, err := c.Simplify().CheckString("1.5.0-beta")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
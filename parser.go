@@ -0,0 +1,440 @@
+package vc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The expression grammar accepted by NewConstraint:
+//
+//	expr   = and { ("||" | "or") and }
+//	and    = unary { ["&&" | "and"] unary }
+//	unary  = ("!" | "not") unary | "(" expr ")" | clause
+//	clause = comparisons separated by spaces or a hyphen range
+//
+// A clause is the flat syntax, e.g. ">=1.2 <2" or "1.2 - 1.4", which is
+// parsed by parseGroup in the dialect of the parser. Juxtaposed operands are
+// joined with AND. The parsed tree is normalized into OR groups of AND
+// comparators, with negations pushed down into the comparators.
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenClause
+	tokenOr
+	tokenAnd
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// Byte offset of the token in the expression
+	offset int
+}
+
+// tokenize splits an expression into tokens. Text between operators and
+// parentheses is collected into clauses.
+func tokenize(expr string) []token {
+	var tokens []token
+	start, end := -1, -1
+	// After a negation, the clause ends at the first space, so that
+	// "!^1.2 <3" negates ^1.2 only.
+	single := false
+
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, token{kind: tokenClause, text: expr[start:end], offset: start})
+		}
+		start, end = -1, -1
+	}
+	emit := func(kind tokenKind, i, n int) {
+		flush()
+		tokens = append(tokens, token{kind: kind, text: expr[i : i+n], offset: i})
+		single = kind == tokenNot
+	}
+
+	for i := 0; i < len(expr); {
+		ch := expr[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			if single && start >= 0 {
+				flush()
+				single = false
+			}
+			i++
+		case ch == '(':
+			emit(tokenLParen, i, 1)
+			i++
+		case ch == ')':
+			emit(tokenRParen, i, 1)
+			i++
+		case strings.HasPrefix(expr[i:], "||"):
+			emit(tokenOr, i, 2)
+			i += 2
+		case strings.HasPrefix(expr[i:], "&&"):
+			emit(tokenAnd, i, 2)
+			i += 2
+		case ch == '!' && (isNegation(expr[i+1:]) || negatesWildcard(expr[i+1:])):
+			// !=1.2.x is lexed as ! followed by =1.2.x
+			emit(tokenNot, i, 1)
+			i++
+		case start < 0 && isKeyword(expr[i:], "or"):
+			emit(tokenOr, i, 2)
+			i += 2
+		case start < 0 && isKeyword(expr[i:], "and"):
+			emit(tokenAnd, i, 3)
+			i += 3
+		case start < 0 && isKeyword(expr[i:], "not"):
+			emit(tokenNot, i, 3)
+			i += 3
+		default:
+			if start < 0 {
+				start = i
+			}
+			i++
+			end = i
+			// A keyword may follow a space inside a clause.
+			if i < len(expr) && expr[i] == ' ' && nextIsKeyword(expr[i:]) {
+				flush()
+			}
+		}
+	}
+	flush()
+	return append(tokens, token{kind: tokenEOF, offset: len(expr)})
+}
+
+// isNegation tests if a "!" followed by s is a negation rather than the
+// != operator or the ! alias of a comparison such as !1.5.
+func isNegation(s string) bool {
+	if s == "" {
+		return true
+	}
+	r := rune(s[0])
+	return r != '=' && r != '.' && r != '*' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// negatesWildcard tests if a "!" followed by s excludes an x-range, as in
+// !=1.2.x or !1.2.x. !=* is left to the comparison parser, which matches no
+// version, not even a prerelease of 0.0.0.
+func negatesWildcard(s string) bool {
+	end := strings.IndexAny(s, " \t\n\r()|&")
+	if end < 0 {
+		end = len(s)
+	}
+	ver := strings.TrimPrefix(s[:end], OperatorEq)
+	if !hasWildcard(ver) {
+		return false
+	}
+	_, precision := expandWildcard(ver)
+	return precision > 0
+}
+
+// isKeyword tests if s starts with the keyword followed by a space, a
+// parenthesis or the end of the expression.
+func isKeyword(s, keyword string) bool {
+	if !strings.HasPrefix(s, keyword) {
+		return false
+	}
+	rest := s[len(keyword):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '('
+}
+
+func nextIsKeyword(s string) bool {
+	s = strings.TrimLeft(s, " \t")
+	return isKeyword(s, "or") || isKeyword(s, "and") || isKeyword(s, "not")
+}
+
+// node is a parsed expression.
+type node interface {
+	// dnf returns the expression, or its negation if neg is set, as OR
+	// groups of AND comparators. Negated comparators take src as their
+	// original value.
+	dnf(neg bool, src string) ([][]*constraint, error)
+}
+
+type clauseNode struct {
+	atoms []*constraint
+}
+
+type andNode struct {
+	left, right node
+}
+
+type orNode struct {
+	left, right node
+}
+
+type notNode struct {
+	operand node
+	// The text of the negation, e.g. !(=1.4.5 || =1.4.6)
+	src string
+}
+
+func (n *clauseNode) dnf(neg bool, src string) ([][]*constraint, error) {
+	if !neg {
+		return [][]*constraint{append([]*constraint(nil), n.atoms...)}, nil
+	}
+	result := make([][]*constraint, 0, len(n.atoms))
+	for _, v := range n.atoms {
+		result = append(result, []*constraint{v.negate(src)})
+	}
+	return result, nil
+}
+
+func (n *andNode) dnf(neg bool, src string) ([][]*constraint, error) {
+	left, right, err := dnfOperands(n.left, n.right, neg, src)
+	if err != nil {
+		return nil, err
+	}
+	if neg {
+		return orGroups(left, right)
+	}
+	return andGroups(left, right)
+}
+
+func (n *orNode) dnf(neg bool, src string) ([][]*constraint, error) {
+	left, right, err := dnfOperands(n.left, n.right, neg, src)
+	if err != nil {
+		return nil, err
+	}
+	if neg {
+		return andGroups(left, right)
+	}
+	return orGroups(left, right)
+}
+
+func (n *notNode) dnf(neg bool, _ string) ([][]*constraint, error) {
+	if neg {
+		return n.operand.dnf(false, "")
+	}
+	return n.operand.dnf(true, n.src)
+}
+
+// dnfOperands returns the OR groups of both operands of a binary node.
+func dnfOperands(left, right node, neg bool, src string) ([][]*constraint, [][]*constraint, error) {
+	l, err := left.dnf(neg, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := right.dnf(neg, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+// maxGroups bounds the number of OR groups an expression may expand into.
+// Distributing AND over OR multiplies the groups of its operands, so a
+// short expression such as a chain of negated ranges could otherwise
+// expand into millions of groups.
+const maxGroups = 4096
+
+// errTooComplex is returned for expressions expanding into more than
+// maxGroups OR groups.
+var errTooComplex = &ParseError{
+	Reason: fmt.Sprintf("expression too complex, it expands into more than %d OR groups", maxGroups),
+	Err:    ErrInvalidConstraint,
+}
+
+// andGroups joins two lists of OR groups with AND by distributing one over
+// the other.
+func andGroups(a, b [][]*constraint) ([][]*constraint, error) {
+	if len(a)*len(b) > maxGroups {
+		return nil, errTooComplex
+	}
+	result := make([][]*constraint, 0, len(a)*len(b))
+	for _, ga := range a {
+		for _, gb := range b {
+			g := make([]*constraint, 0, len(ga)+len(gb))
+			g = append(g, ga...)
+			result = append(result, append(g, gb...))
+		}
+	}
+	return result, nil
+}
+
+// orGroups joins two lists of OR groups with OR.
+func orGroups(a, b [][]*constraint) ([][]*constraint, error) {
+	if len(a)+len(b) > maxGroups {
+		return nil, errTooComplex
+	}
+	return append(a, b...), nil
+}
+
+// negations maps an operator to the operator matching every other version.
+var negations = map[string]string{
+	OperatorGte: OperatorLt,
+	OperatorGt:  OperatorLte,
+	OperatorLte: OperatorGt,
+	OperatorLt:  OperatorGte,
+	OperatorEq:  OperatorNe,
+	OperatorNe:  OperatorEq,
+	"!":         OperatorEq,
+}
+
+// negate returns the comparator matching every version c does not.
+func (c *constraint) negate(src string) *constraint {
+	return &constraint{
+		original: src,
+		version:  c.version,
+		operator: negations[c.operator],
+		com:      c.com,
+	}
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+	fn     New
+	opts   *options
+	// The aliases being resolved, to detect cycles
+	resolving []string
+}
+
+// parseExpression parses an expression into OR groups of AND comparators.
+func parseExpression(expr string, fn New, opts *options) ([][]*constraint, error) {
+	n, err := newParser(expr, fn, opts).parse()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := n.dnf(false, "")
+	if err != nil {
+		return nil, relocate(err, expr, 0, "")
+	}
+	return groups, nil
+}
+
+func newParser(expr string, fn New, opts *options) *parser {
+	return &parser{input: expr, tokens: tokenize(expr), fn: fn, opts: opts}
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, "unexpected "+describeToken(t))
+	}
+	return n, nil
+}
+
+// errorAt creates a ParseError for the token.
+func (p *parser) errorAt(t token, reason string) *ParseError {
+	return &ParseError{Input: p.input, Offset: t.offset, Token: t.text, Reason: reason, Err: ErrInvalidConstraint}
+}
+
+func describeToken(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenClause:
+		return "comparison"
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenClause, tokenNot, tokenLParen:
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if k := p.peek().kind; p.opts.dialect == DialectNPM && (k == tokenOr || k == tokenEOF) {
+		// npm reads a missing OR group as *.
+		return &clauseNode{}, nil
+	}
+	t := p.next()
+	switch t.kind {
+	case tokenNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		end := p.tokens[p.pos-1]
+		src := strings.TrimSpace(p.input[t.offset : end.offset+len(end.text)])
+		return &notNode{operand: operand, src: src}, nil
+	case tokenLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorAt(closing, `missing ")"`)
+		}
+		return n, nil
+	case tokenClause:
+		if p.opts.resolve != nil {
+			return p.parseAliases(t)
+		}
+		return p.parseClause(t.text, t.offset)
+	default:
+		return nil, p.errorAt(t, "expected a comparison, found "+describeToken(t))
+	}
+}
+
+// parseClause parses the flat syntax found at offset in the input.
+func (p *parser) parseClause(text string, offset int) (node, error) {
+	switch p.opts.dialect {
+	case DialectNPM:
+		text = attachOperators(text)
+	case DialectCargo:
+		text = attachOperators(strings.ReplaceAll(text, ",", " "))
+	}
+	var atoms []*constraint
+	if err := parseGroup(text, p.fn, p.opts.dialect, &atoms); err != nil {
+		return nil, relocate(err, p.input, offset, text)
+	}
+	return &clauseNode{atoms: atoms}, nil
+}
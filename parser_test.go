@@ -0,0 +1,157 @@
+package vc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected []token
+	}{
+		{">=1.2 <2 || >=3", []token{
+			{tokenClause, ">=1.2 <2", 0},
+			{tokenOr, "||", 9},
+			{tokenClause, ">=3", 12},
+		}},
+		{"(>=1.2 <2)&&!(=1.4.5)", []token{
+			{tokenLParen, "(", 0},
+			{tokenClause, ">=1.2 <2", 1},
+			{tokenRParen, ")", 9},
+			{tokenAnd, "&&", 10},
+			{tokenNot, "!", 12},
+			{tokenLParen, "(", 13},
+			{tokenClause, "=1.4.5", 14},
+			{tokenRParen, ")", 20},
+		}},
+		{">=1 and not ^1.5 <3 or 1.0 - 2.0", []token{
+			{tokenClause, ">=1", 0},
+			{tokenAnd, "and", 4},
+			{tokenNot, "not", 8},
+			{tokenClause, "^1.5", 12},
+			{tokenClause, "<3", 17},
+			{tokenOr, "or", 20},
+			{tokenClause, "1.0 - 2.0", 23},
+		}},
+		{"!1.5 !=1.6 !^2", []token{
+			{tokenClause, "!1.5 !=1.6", 0},
+			{tokenNot, "!", 11},
+			{tokenClause, "^2", 12},
+		}},
+	}
+
+	for _, tc := range tests {
+		got := tokenize(tc.in)
+		assert.Equal(t, tokenEOF, got[len(got)-1].kind)
+		assert.Equal(t, tc.expected, got[:len(got)-1], tc.in)
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+		err      bool
+	}{
+		{">=1.2 <2 || >=3", ">=1.2.0 <2.0.0 || >=3.0.0", false},
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)",
+			">=1.2.0 <2.0.0 !=1.4.5 !=1.4.6 || >=3.0.0 !=1.4.5 !=1.4.6", false},
+		{"(^1 or ^3) and not ~1.2", ">=1.0.0 <1.2.0 <2.0.0 || >=1.0.0 >=1.3.0 <2.0.0 || " +
+			">=3.0.0 <1.2.0 <4.0.0 || >=1.3.0 >=3.0.0 <4.0.0", false},
+		{"!(>=1 <2)", "<1.0.0 || >=2.0.0", false},
+		{"not not ^1", ">=1.0.0 <2.0.0", false},
+		{"!!=1.5", "1.5.0", false},
+		{"^1 (<1.5 || >1.8)", ">=1.0.0 <1.5.0 <2.0.0 || >=1.0.0 >1.8.0 <2.0.0", false},
+		{"1.0 - 2.0 || (3.0 - 4.0)", ">=1.0.0 <=2.0.0 || >=3.0.0 <=4.0.0", false},
+		{"(", "", true},
+		{"()", "", true},
+		{"(^1", "", true},
+		{"^1)", "", true},
+		{"^1 ||", "", true},
+		{"|| ^1", "", true},
+		{"^1 && || ^2", "", true},
+		{"not", "", true},
+		{"^1 | ^2", "", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.in, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		})
+		if tc.err {
+			assert.Error(t, err, tc.in)
+			continue
+		}
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.expected, c.Canonical(), tc.in)
+		assert.Equal(t, tc.in, c.String())
+	}
+}
+
+func TestExpressionCheck(t *testing.T) {
+	tests := []struct {
+		con   string
+		ver   string
+		valid bool
+	}{
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)", "1.4.4", true},
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)", "1.4.5", false},
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)", "1.4.6", false},
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)", "2.5.0", false},
+		{"(>=1.2 <2 || >=3) && !(=1.4.5 || =1.4.6)", "3.1.0", true},
+		{"^1 and not ~1.2", "1.2.5", false},
+		{"^1 and not ~1.2", "1.3.0", true},
+		{"not ^1 or =1.5.0", "1.5.0", true},
+		{"not ^1 or =1.5.0", "1.6.0", false},
+		{"not ^1 or =1.5.0", "2.0.0", true},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		ok, err := c.CheckString(tc.ver)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.valid, ok, "%s %s", tc.con, tc.ver)
+	}
+}
+
+func TestExpressionSource(t *testing.T) {
+	c := mustSemverConstraint(t, "^1 && !(=1.4.5 || =1.4.6)")
+	v, _ := NewSemverStr("1.4.6")
+	ok, errs := c.Validate(v)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "1.4.6 is equal to 1.4.6 (from !(=1.4.5 || =1.4.6))")
+}
+
+func TestExpressionTooComplex(t *testing.T) {
+	newfn := func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}
+	// Each negation doubles the groups four times, 40 of them would
+	// expand into 2^160 groups.
+	expr := strings.Repeat("!(>=1.0.0 <2.0.0 !=1.5.0 !=1.6.0) ", 40)
+	_, err := NewConstraint(expr, newfn)
+	var pe *ParseError
+	if assert.True(t, errors.As(err, &pe)) {
+		assert.ErrorIs(t, err, ErrInvalidConstraint)
+		assert.Contains(t, pe.Reason, "expression too complex")
+		assert.Equal(t, expr, pe.Input)
+	}
+
+	// Expressions below the limit still parse.
+	c, err := NewConstraint(strings.Repeat("!(>=1.0.0 <2.0.0 !=1.5.0 !=1.6.0) ", 5), newfn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, len(c.Groups()))
+
+	spec := strings.TrimSuffix(strings.Repeat("!=1.0,", 20), ",")
+	_, err = NewPEP440Specifier(spec)
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+
+	rel := strings.TrimSuffix(strings.Repeat(">= 1.0 | << 0.5, ", 20), ", ")
+	_, err = NewDebianRelation(rel)
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+}
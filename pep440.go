@@ -0,0 +1,559 @@
+package vc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The version pattern of PEP 440, Appendix B, which also accepts the
+// alternative spellings that normalize to a canonical version.
+const pep440Reg = `^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_\.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_\.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_\.]?(?P<post_l>post|rev|r)[-_\.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_\.]?(?P<dev_l>dev)[-_\.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_\.][a-z0-9]+)*))?\s*$`
+
+var pep440Regex = regexp.MustCompile(`(?i)` + pep440Reg)
+
+var _ Ordered = &PEP440{}
+
+// PEP440 is a Python package version as specified by PEP 440, e.g.
+// 1!2.0.1rc1.post2.dev3+ubuntu.1. Versions are normalized when parsed, so
+// 1.0-ALPHA.1 and 1.0a1 are the same version.
+type PEP440 struct {
+	epoch   uint64
+	release []uint64
+	// The prerelease phase, "a", "b" or "rc", empty if there is none
+	pre      string
+	preN     uint64
+	post     bool
+	postN    uint64
+	dev      bool
+	devN     uint64
+	local    []string
+	original string
+	// Whether the version stands above every post and local release of
+	// itself rather than for a real version. Specifiers use it to exclude
+	// those from >V.
+	abovePosts bool
+}
+
+// NewPEP440Str parses a PEP 440 version and returns an instance of PEP440
+// or an error if unable to parse the version. Parse failures are reported as
+// a *ParseError wrapping ErrInvalidPEP440.
+func NewPEP440Str(ver string) (*PEP440, error) {
+	m := pep440Regex.FindStringSubmatch(ver)
+	if m == nil {
+		return nil, &ParseError{Input: ver, Token: ver, Reason: "not a PEP 440 version", Err: ErrInvalidPEP440}
+	}
+	group := func(name string) string {
+		return m[pep440Regex.SubexpIndex(name)]
+	}
+	number := func(s string) (uint64, error) {
+		if s == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, &ParseError{Input: ver, Offset: strings.Index(ver, s), Token: s,
+				Reason: "number out of range", Err: ErrInvalidPEP440}
+		}
+		return n, nil
+	}
+
+	v := &PEP440{original: ver}
+	var err error
+	if v.epoch, err = number(group("epoch")); err != nil {
+		return nil, err
+	}
+	for _, p := range strings.Split(group("release"), ".") {
+		n, err := number(p)
+		if err != nil {
+			return nil, err
+		}
+		v.release = append(v.release, n)
+	}
+	if group("pre") != "" {
+		switch strings.ToLower(group("pre_l")) {
+		case "a", "alpha":
+			v.pre = "a"
+		case "b", "beta":
+			v.pre = "b"
+		default:
+			v.pre = "rc"
+		}
+		if v.preN, err = number(group("pre_n")); err != nil {
+			return nil, err
+		}
+	}
+	if group("post") != "" {
+		v.post = true
+		n := group("post_n1")
+		if n == "" {
+			n = group("post_n2")
+		}
+		if v.postN, err = number(n); err != nil {
+			return nil, err
+		}
+	}
+	if group("dev") != "" {
+		v.dev = true
+		if v.devN, err = number(group("dev_n")); err != nil {
+			return nil, err
+		}
+	}
+	if local := group("local"); local != "" {
+		v.local = strings.FieldsFunc(strings.ToLower(local), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+	return v, nil
+}
+
+// String returns the normalized form of the version.
+func (v *PEP440) String() string {
+	var b strings.Builder
+	b.WriteString(v.public())
+	if v.abovePosts {
+		b.WriteString(".post*")
+	}
+	if len(v.local) > 0 {
+		b.WriteString("+" + strings.Join(v.local, "."))
+	}
+	return b.String()
+}
+
+// public returns the normalized version without its local part.
+func (v *PEP440) public() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		_, _ = fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+	b.WriteString(v.Version())
+	if v.pre != "" {
+		_, _ = fmt.Fprintf(&b, "%s%d", v.pre, v.preN)
+	}
+	if v.post {
+		_, _ = fmt.Fprintf(&b, ".post%d", v.postN)
+	}
+	if v.dev {
+		_, _ = fmt.Fprintf(&b, ".dev%d", v.devN)
+	}
+	return b.String()
+}
+
+// Original returns the original value passed in to be parsed.
+func (v *PEP440) Original() string {
+	return v.original
+}
+
+// Version returns the release segment, e.g. 1.2.3.4.
+func (v *PEP440) Version() string {
+	parts := make([]string, 0, len(v.release))
+	for _, n := range v.release {
+		parts = append(parts, strconv.FormatUint(n, 10))
+	}
+	return strings.Join(parts, ".")
+}
+
+// Epoch returns the epoch, 0 if the version has none.
+func (v *PEP440) Epoch() uint64 {
+	return v.epoch
+}
+
+// Release returns the numbers of the release segment.
+func (v *PEP440) Release() []uint64 {
+	return append([]uint64(nil), v.release...)
+}
+
+// Major returns the first number of the release segment.
+func (v *PEP440) Major() uint64 {
+	return v.part(0)
+}
+
+// Minor returns the second number of the release segment, 0 if there is
+// none.
+func (v *PEP440) Minor() uint64 {
+	return v.part(1)
+}
+
+// Patch returns the third number of the release segment, 0 if there is none.
+func (v *PEP440) Patch() uint64 {
+	return v.part(2)
+}
+
+func (v *PEP440) part(i int) uint64 {
+	if i < len(v.release) {
+		return v.release[i]
+	}
+	return 0
+}
+
+// Prerelease returns the pre and dev release parts, e.g. rc1.dev2, or an
+// empty string for final and post releases.
+func (v *PEP440) Prerelease() string {
+	var parts []string
+	if v.pre != "" {
+		parts = append(parts, fmt.Sprintf("%s%d", v.pre, v.preN))
+	}
+	if v.dev {
+		parts = append(parts, fmt.Sprintf("dev%d", v.devN))
+	}
+	return strings.Join(parts, ".")
+}
+
+// Local returns the local version label, e.g. ubuntu.1.
+func (v *PEP440) Local() string {
+	return strings.Join(v.local, ".")
+}
+
+// IsPostRelease tests if the version is a post release.
+func (v *PEP440) IsPostRelease() bool {
+	return v.post
+}
+
+// IncMajor produces the next major release, e.g. 2.0 after 1.4.2rc1.
+func (v *PEP440) IncMajor() Comparable {
+	return v.withRelease(v.Major()+1, 0)
+}
+
+// IncMinor produces the next minor release, e.g. 1.5 after 1.4.2rc1.
+func (v *PEP440) IncMinor() Comparable {
+	return v.withRelease(v.Major(), v.Minor()+1)
+}
+
+// IncPatch produces the next patch release. A pre or dev release produces
+// its final release, e.g. 1.4.2 after 1.4.2rc1.
+func (v *PEP440) IncPatch() Comparable {
+	if v.Prerelease() != "" {
+		return v.withRelease(v.release...)
+	}
+	return v.withRelease(v.Major(), v.Minor(), v.Patch()+1)
+}
+
+func (v *PEP440) withRelease(release ...uint64) *PEP440 {
+	next := &PEP440{epoch: v.epoch, release: append([]uint64(nil), release...)}
+	next.original = next.String()
+	return next
+}
+
+// CompareTo compares the version to another one in the order of PEP 440.
+// Versions of other types are compared by their major, minor, patch and
+// prerelease parts.
+func (v *PEP440) CompareTo(other Comparable) int {
+	o, ok := other.(*PEP440)
+	if !ok {
+		return compareParts(v, other)
+	}
+	if d := compareSegment(v.epoch, o.epoch); d != 0 {
+		return d
+	}
+	if d := compareRelease(v.release, o.release); d != 0 {
+		return d
+	}
+	for _, d := range [...]int{
+		compareKeys(v.preKey(), o.preKey()),
+		compareKeys(v.postKey(), o.postKey()),
+		compareKeys(v.devKey(), o.devKey()),
+	} {
+		if d != 0 {
+			return d
+		}
+	}
+	return v.compareLocal(o)
+}
+
+// A sort key of a version part: the rank orders missing parts before (-1) or
+// after (1) all present ones (0), which are ordered by their values.
+type pep440Key struct {
+	rank   int
+	values [2]uint64
+}
+
+func compareKeys(a, b pep440Key) int {
+	if a.rank != b.rank {
+		if a.rank < b.rank {
+			return -1
+		}
+		return 1
+	}
+	if d := compareSegment(a.values[0], b.values[0]); d != 0 {
+		return d
+	}
+	return compareSegment(a.values[1], b.values[1])
+}
+
+var pep440Phases = map[string]uint64{"a": 0, "b": 1, "rc": 2}
+
+func (v *PEP440) preKey() pep440Key {
+	switch {
+	case v.pre != "":
+		return pep440Key{values: [2]uint64{pep440Phases[v.pre], v.preN}}
+	case v.dev && !v.post:
+		// 1.0.dev0 sorts before 1.0a0
+		return pep440Key{rank: -1}
+	default:
+		return pep440Key{rank: 1}
+	}
+}
+
+func (v *PEP440) postKey() pep440Key {
+	switch {
+	case v.abovePosts:
+		return pep440Key{rank: 1}
+	case v.post:
+		return pep440Key{values: [2]uint64{v.postN}}
+	default:
+		return pep440Key{rank: -1}
+	}
+}
+
+func (v *PEP440) devKey() pep440Key {
+	if v.dev {
+		return pep440Key{values: [2]uint64{v.devN}}
+	}
+	return pep440Key{rank: 1}
+}
+
+// compareLocal orders local labels. A version without one sorts first,
+// numeric segments sort after alphanumeric ones and a label that is a
+// prefix of another sorts first.
+func (v *PEP440) compareLocal(o *PEP440) int {
+	if v.abovePosts || o.abovePosts {
+		return boolCompare(v.abovePosts, o.abovePosts)
+	}
+	for i := 0; i < len(v.local) && i < len(o.local); i++ {
+		a, aerr := strconv.ParseUint(v.local[i], 10, 64)
+		b, berr := strconv.ParseUint(o.local[i], 10, 64)
+		var d int
+		switch {
+		case aerr == nil && berr == nil:
+			d = compareSegment(a, b)
+		case aerr == nil || berr == nil:
+			d = boolCompare(aerr == nil, berr == nil)
+		default:
+			d = strings.Compare(v.local[i], o.local[i])
+		}
+		if d != 0 {
+			return d
+		}
+	}
+	return compareSegment(uint64(len(v.local)), uint64(len(o.local)))
+}
+
+// compareRelease compares release segments, padding the shorter one with
+// zeros.
+func compareRelease(a, b []uint64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y uint64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if d := compareSegment(x, y); d != 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Operators of PEP 440 version specifiers.
+const (
+	pep440Compatible = "~="
+	pep440Arbitrary  = "==="
+	pep440Eq         = "=="
+)
+
+var pep440ClauseRegex = regexp.MustCompile(`^(\s*)(~=|===|==|!=|<=|>=|<|>)\s*(\S+?)\s*$`)
+
+// NewPEP440Specifier parses a PEP 440 version specifier, e.g.
+// "~=1.4.2, !=1.4.5" or "==2.*", into Constraints whose versions are PEP440.
+// Clauses are separated by commas and must all be satisfied, an empty
+// specifier matches any version.
+//
+// Each clause is translated into the comparators it stands for:
+//
+//	~=1.4.2   -->  >=1.4.2 <1.5.dev0
+//	==1.4.*   -->  >=1.4.dev0 <1.5.dev0
+//	==1.4     -->  >=1.4 <1.4.post0.dev0, i.e. 1.4 and its local versions
+//	<=1.4     -->  <1.4.post0.dev0
+//	<1.4      -->  <1.4.dev0, excluding the prereleases of 1.4
+//	>1.4      -->  >1.4.post*, excluding the post and local releases of 1.4
+//	!=1.4.*   -->  <1.4.dev0 || >=1.5.dev0
+//
+// "===" compares the versions as written, ignoring case: "===1.0" accepts
+// 1.0 but not 1.0.0. Constraints with such a clause do not support the set
+// operations, see ErrArbitraryEquality.
+//
+// As required by PEP 440, prereleases are excluded unless a clause other
+// than "!=" names one, e.g. ">=1.0b1" accepts 1.0rc1.
+// WithPrereleasePolicy overrides this, other options are ignored.
+func NewPEP440Specifier(spec string, opts ...Option) (*Constraints, error) {
+	o := newOptions(opts)
+	policy := PrereleaseExclude
+	groups := [][]*constraint{{}}
+	offset := 0
+	for _, clause := range strings.Split(spec, ",") {
+		if strings.TrimSpace(clause) != "" || strings.Contains(spec, ",") {
+			gs, pre, err := parsePEP440Clause(clause)
+			if err != nil {
+				return nil, relocate(err, spec, offset, clause)
+			}
+			if pre {
+				policy = PrereleaseIncludeAll
+			}
+			if groups, err = andGroups(groups, gs); err != nil {
+				return nil, relocate(err, spec, 0, "")
+			}
+		}
+		offset += len(clause) + 1
+	}
+	if o.prereleaseSet {
+		policy = o.prerelease
+	}
+	return &Constraints{
+		constraints: groups,
+		newfn: func(s string) (Comparable, error) {
+			return NewPEP440Str(s)
+		},
+		original: spec,
+		policy:   policy,
+		opts:     opts,
+		syntax:   "PEP 440 specifier",
+	}, nil
+}
+
+// parsePEP440Clause parses a single clause of a specifier into OR groups of
+// AND comparators. It reports whether the clause asks for prereleases.
+func parsePEP440Clause(clause string) ([][]*constraint, bool, error) {
+	m := pep440ClauseRegex.FindStringSubmatch(clause)
+	if m == nil {
+		trimmed := strings.TrimSpace(clause)
+		if trimmed == "" {
+			return nil, false, &ParseError{Input: clause, Reason: "empty clause", Err: ErrInvalidConstraint}
+		}
+		return nil, false, &ParseError{Input: clause, Offset: strings.Index(clause, trimmed), Token: trimmed,
+			Reason: "expected an operator followed by a version", Err: ErrInvalidConstraint}
+	}
+	op, ver := m[2], m[3]
+	verOffset := strings.LastIndex(clause, ver)
+	fail := func(reason string) error {
+		return &ParseError{Input: clause, Offset: verOffset, Token: ver, Reason: reason, Err: ErrInvalidConstraint}
+	}
+
+	prefix := strings.HasSuffix(ver, ".*")
+	if prefix && op != pep440Eq && op != OperatorNe {
+		return nil, false, fail("prefix matching is only allowed with == and !=")
+	}
+	v, err := NewPEP440Str(strings.TrimSuffix(ver, ".*"))
+	if err != nil {
+		return nil, false, relocate(err, clause, verOffset, ver)
+	}
+	src := strings.TrimSpace(clause)
+	atom := func(op string, com *PEP440) *constraint {
+		return &constraint{original: src, version: com.String(), operator: op, com: com}
+	}
+	pre := v.Prerelease() != "" && op != OperatorNe
+
+	if op == pep440Arbitrary {
+		return [][]*constraint{{{original: src, version: ver, operator: pep440Arbitrary, com: v}}}, pre, nil
+	}
+	if len(v.local) > 0 {
+		if op != pep440Eq && op != OperatorNe {
+			return nil, false, fail("local versions are only allowed with == and !=")
+		}
+		if prefix {
+			return nil, false, fail("local versions do not allow prefix matching")
+		}
+		if op == OperatorNe {
+			return [][]*constraint{{atom(OperatorNe, v)}}, false, nil
+		}
+		return [][]*constraint{{atom(OperatorEq, v)}}, pre, nil
+	}
+	if prefix && (v.pre != "" || v.post || v.dev) {
+		return nil, false, fail("prefix matching is only allowed on a release segment")
+	}
+
+	switch op {
+	case pep440Compatible:
+		if len(v.release) < 2 {
+			return nil, false, fail("~= requires at least two release segments")
+		}
+		upper := v.prefixUpper(v.release[:len(v.release)-1])
+		return [][]*constraint{{atom(OperatorGte, v), atom(OperatorLt, upper)}}, pre, nil
+	case pep440Eq, OperatorNe:
+		lower, upper := v, v.successor()
+		if prefix {
+			lower, upper = v.withDev(v.release), v.prefixUpper(v.release)
+		}
+		if op == OperatorNe {
+			return [][]*constraint{{atom(OperatorLt, lower)}, {atom(OperatorGte, upper)}}, false, nil
+		}
+		return [][]*constraint{{atom(OperatorGte, lower), atom(OperatorLt, upper)}}, pre, nil
+	case OperatorGte:
+		return [][]*constraint{{atom(OperatorGte, v)}}, pre, nil
+	case OperatorLte:
+		return [][]*constraint{{atom(OperatorLt, v.successor())}}, pre, nil
+	case OperatorLt:
+		base := v.withDev(v.release)
+		switch {
+		case v.Prerelease() != "":
+			return [][]*constraint{{atom(OperatorLt, v)}}, pre, nil
+		case v.post:
+			// The prereleases of the base release are excluded, its post
+			// releases are not.
+			final := v.withRelease(v.release...)
+			return [][]*constraint{{atom(OperatorLt, base)}, {atom(OperatorGte, final), atom(OperatorLt, v)}}, false, nil
+		default:
+			return [][]*constraint{{atom(OperatorLt, base)}}, false, nil
+		}
+	default:
+		if v.post || v.dev {
+			return [][]*constraint{{atom(OperatorGte, v.successor())}}, pre, nil
+		}
+		above := *v
+		above.abovePosts = true
+		return [][]*constraint{{atom(OperatorGt, &above)}}, pre, nil
+	}
+}
+
+// successor returns the smallest version above the version and its local
+// versions, e.g. 1.4.post0.dev0 after 1.4.
+func (v *PEP440) successor() *PEP440 {
+	next := *v
+	next.local = nil
+	switch {
+	case v.dev:
+		next.devN++
+	case v.post:
+		next.postN++
+		next.dev, next.devN = true, 0
+	default:
+		next.post, next.postN = true, 0
+		next.dev, next.devN = true, 0
+	}
+	next.original = next.String()
+	return &next
+}
+
+// prefixUpper returns the first version not matching the release prefix,
+// e.g. 1.5.dev0 for 1.4.*.
+func (v *PEP440) prefixUpper(prefix []uint64) *PEP440 {
+	release := append([]uint64(nil), prefix...)
+	release[len(release)-1]++
+	return v.withDev(release)
+}
+
+// withDev returns the first dev release of a release, e.g. 1.5.dev0.
+func (v *PEP440) withDev(release []uint64) *PEP440 {
+	next := v.withRelease(release...)
+	next.dev = true
+	next.original = next.String()
+	return next
+}
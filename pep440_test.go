@@ -0,0 +1,354 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPEP440Str(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+		err      bool
+	}{
+		{"1.0", "1.0", false},
+		{"v1.0", "1.0", false},
+		{"1.0.0.0.1", "1.0.0.0.1", false},
+		{"1!2.0", "1!2.0", false},
+		{"0!2.0", "2.0", false},
+		{"1.0a1", "1.0a1", false},
+		{"1.0-ALPHA.1", "1.0a1", false},
+		{"1.0beta2", "1.0b2", false},
+		{"1.0c1", "1.0rc1", false},
+		{"1.0pre1", "1.0rc1", false},
+		{"1.0preview_1", "1.0rc1", false},
+		{"1.0rc", "1.0rc0", false},
+		{"1.0-1", "1.0.post1", false},
+		{"1.0.post", "1.0.post0", false},
+		{"1.0rev2", "1.0.post2", false},
+		{"1.0-r3", "1.0.post3", false},
+		{"1.0dev", "1.0.dev0", false},
+		{"1.0-dev.4", "1.0.dev4", false},
+		{"1.0a1.post2.dev3", "1.0a1.post2.dev3", false},
+		{"1.0+Ubuntu-1", "1.0+ubuntu.1", false},
+		{"1.0+abc_5.x", "1.0+abc.5.x", false},
+		{"  1.0  ", "1.0", false},
+		{"", "", true},
+		{"1.", "", true},
+		{"1.0-", "", true},
+		{"1.0+", "", true},
+		{"1.0+local+two", "", true},
+		{"1.0foo", "", true},
+		{"1.0-alpha-beta", "", true},
+		{"1.99999999999999999999", "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewPEP440Str(tc.version)
+		if tc.err {
+			assert.Error(t, err, tc.version)
+			assert.True(t, errors.Is(err, ErrInvalidPEP440), tc.version)
+			continue
+		}
+		if assert.NoError(t, err, tc.version) {
+			assert.Equal(t, tc.expected, v.String())
+			assert.Equal(t, tc.version, v.Original())
+		}
+	}
+}
+
+func TestPEP440Parts(t *testing.T) {
+	v, err := NewPEP440Str("2!1.4.2.7rc1.post2.dev3+local.7")
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(2), v.Epoch())
+	assert.Equal(t, []uint64{1, 4, 2, 7}, v.Release())
+	assert.Equal(t, uint64(1), v.Major())
+	assert.Equal(t, uint64(4), v.Minor())
+	assert.Equal(t, uint64(2), v.Patch())
+	assert.Equal(t, "1.4.2.7", v.Version())
+	assert.Equal(t, "rc1.dev3", v.Prerelease())
+	assert.Equal(t, "local.7", v.Local())
+	assert.True(t, v.IsPostRelease())
+
+	v, err = NewPEP440Str("3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), v.Minor())
+	assert.Equal(t, uint64(0), v.Patch())
+	assert.Equal(t, "", v.Prerelease())
+}
+
+func TestPEP440Inc(t *testing.T) {
+	tests := []struct {
+		version string
+		major   string
+		minor   string
+		patch   string
+	}{
+		{"1.4.2", "2.0", "1.5", "1.4.3"},
+		{"1.4", "2.0", "1.5", "1.4.1"},
+		{"1!1.4.2.post1", "1!2.0", "1!1.5", "1!1.4.3"},
+		{"1.4.2rc1", "2.0", "1.5", "1.4.2"},
+		{"1.4.dev2", "2.0", "1.5", "1.4"},
+	}
+
+	for _, tc := range tests {
+		v, err := NewPEP440Str(tc.version)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.major, formatVersion(v.IncMajor()), tc.version)
+		assert.Equal(t, tc.minor, formatVersion(v.IncMinor()), tc.version)
+		assert.Equal(t, tc.patch, formatVersion(v.IncPatch()), tc.version)
+	}
+}
+
+// The versions are in the order of the examples of PEP 440, with
+// additions from the test suite of packaging.
+func TestPEP440Compare(t *testing.T) {
+	versions := []string{
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.0.15",
+		"1.1.dev1",
+		"1!0.1",
+	}
+
+	for i := range versions {
+		for j := range versions {
+			v1, err := NewPEP440Str(versions[i])
+			assert.NoError(t, err)
+			v2, err := NewPEP440Str(versions[j])
+			assert.NoError(t, err)
+			expected := boolCompare(i > j, j > i)
+			assert.Equal(t, expected, Compare(v1, v2), "%s <=> %s", versions[i], versions[j])
+		}
+	}
+
+	equal := [][2]string{
+		{"1.0", "1.0.0"},
+		{"1.0", "1.0.0.0"},
+		{"1.0a1", "1.0.0alpha1"},
+		{"1.0-1", "1.0.post1"},
+		{"1.0+abc", "1.0+ABC"},
+		{"1.0+1.2", "1.0+1-2"},
+	}
+	for _, tc := range equal {
+		v1, err := NewPEP440Str(tc[0])
+		assert.NoError(t, err)
+		v2, err := NewPEP440Str(tc[1])
+		assert.NoError(t, err)
+		assert.Equal(t, 0, Compare(v1, v2), "%s <=> %s", tc[0], tc[1])
+	}
+
+	// Local labels are compared segment by segment, a shorter prefix first.
+	v1, _ := NewPEP440Str("1.0+abc")
+	v2, _ := NewPEP440Str("1.0+abc.1")
+	assert.Equal(t, -1, Compare(v1, v2))
+}
+
+func TestNewPEP440Specifier(t *testing.T) {
+	tests := []struct {
+		spec     string
+		version  string
+		expected bool
+	}{
+		{"", "1.0", true},
+		{"", "1.0rc1", false},
+		{"==1.0", "1.0", true},
+		{"==1.0", "1.0.0", true},
+		{"==1.0", "1.0+local", true},
+		{"==1.0", "1.0.post1", false},
+		{"==1.0", "1.1", false},
+		{"==1.0+local", "1.0+local", true},
+		{"==1.0+local", "1.0", false},
+		{"!=1.0", "1.0", false},
+		{"!=1.0", "1.0+local", false},
+		{"!=1.0", "1.0.post1", true},
+		{"!=1.0", "0.9", true},
+		{"!=1.0+local", "1.0", true},
+		{"==1.4.*", "1.4", true},
+		{"==1.4.*", "1.4.5", true},
+		{"==1.4.*", "1.4.5.post1", true},
+		{"==1.4.*", "1.4.5+local", true},
+		{"==1.4.*", "1.5", false},
+		{"==1.4.*", "1.3.9", false},
+		{"==1.4.0.*", "1.4", true},
+		{"==1!1.*", "1.1", false},
+		{"==1!1.*", "1!1.1", true},
+		{"!=1.4.*", "1.4.5", false},
+		{"!=1.4.*", "1.5", true},
+		{"!=1.4.*", "1.3", true},
+		{"~=2.2", "2.2", true},
+		{"~=2.2", "2.9", true},
+		{"~=2.2", "3.0", false},
+		{"~=2.2", "2.1", false},
+		{"~=1.4.5", "1.4.9", true},
+		{"~=1.4.5", "1.5", false},
+		{"~=2.2.post3", "2.2.post3", true},
+		{"~=2.2.post3", "2.5", true},
+		{"~=2.2.post3", "2.2", false},
+		{"~=1.4.5a4", "1.4.5rc1", true},
+		{"~=1.4.5a4", "1.4.9", true},
+		{"~=1.4.5a4", "1.5", false},
+		{">=1.0", "1.0", true},
+		{">=1.0", "1.0+local", true},
+		{">=1.0", "2.0", true},
+		{">=1.0", "0.9", false},
+		{">=1.0", "2.0rc1", false},
+		{">=1.0b1", "1.0rc1", true},
+		{">=1.0b1", "2.0rc1", true},
+		{"<=1.0", "1.0", true},
+		{"<=1.0", "1.0+local", true},
+		{"<=1.0", "1.0.post1", false},
+		{"<1.0", "0.9", true},
+		{"<1.0", "1.0", false},
+		{"<1.0", "1.0rc1", false},
+		{"<1.0rc1", "0.9", true},
+		{"<1.0rc1", "1.0b1", true},
+		{"<1.0rc1", "1.0rc1", false},
+		{"<1.0.post1", "1.0", true},
+		{"<1.0.post1", "1.0.post1", false},
+		{">1.0", "1.1", true},
+		{">1.0", "1.0", false},
+		{">1.0", "1.0.post1", false},
+		{">1.0", "1.0+local", false},
+		{">1.0", "1.0.0.1", true},
+		{">1.0.post1", "1.0.post2", true},
+		{">1.0.post1", "1.0.post1+local", false},
+		{"===1.0", "1.0", true},
+		{"===1.0", "1.0+local", false},
+		{"===1.0", "1.0.0", false},
+		{"===1.0RC1", "1.0rc1", true},
+		{"===1.0rc1", "1.0.rc1", false},
+		{">1.4rc1", "1.4rc2", true},
+		{">1.4rc1", "1.4rc1", false},
+		{">=1.0, <2.0, !=1.5.*", "1.4", true},
+		{">=1.0, <2.0, !=1.5.*", "1.5.1", false},
+		{">=1.0, <2.0, !=1.5.*", "2.0", false},
+		{" >= 1.0 ,< 2.0 ", "1.9", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewPEP440Specifier(tc.spec)
+		if !assert.NoError(t, err, tc.spec) {
+			continue
+		}
+		v, err := NewPEP440Str(tc.version)
+		if !assert.NoError(t, err, tc.version) {
+			continue
+		}
+		assert.Equal(t, tc.expected, c.Check(v), "%s %s", tc.spec, tc.version)
+		assert.Equal(t, tc.expected, c.Compile().Check(v), "compiled %s %s", tc.spec, tc.version)
+	}
+}
+
+func TestPEP440SpecifierPrereleases(t *testing.T) {
+	c, err := NewPEP440Specifier(">=1.0", WithPrereleasePolicy(PrereleaseIncludeAll))
+	assert.NoError(t, err)
+	ok, err := c.CheckString("2.0rc1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	c, err = NewPEP440Specifier(">=1.0b1", WithPrereleasePolicy(PrereleaseExclude))
+	assert.NoError(t, err)
+	ok, err = c.CheckString("1.0rc1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "at least 1.0b1, excluding prereleases", c.Describe())
+
+	v, _ := NewPEP440Str("1.0rc1")
+	ok, errs := c.Validate(v)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "1.0rc1 is a prerelease and prereleases are excluded (from >=1.0b1)")
+}
+
+func TestPEP440SpecifierArbitrary(t *testing.T) {
+	c, err := NewPEP440Specifier("===1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "literally 1.0, excluding prereleases", c.Describe())
+	assert.Same(t, c, c.Simplify())
+
+	v, _ := NewPEP440Str("1.0.0")
+	ok, errs := c.Validate(v)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "1.0.0 is not the string 1.0 (from ===1.0)")
+
+	c, err = NewPEP440Specifier("===1.0", WithPrereleasePolicy(PrereleaseIncludeAll))
+	assert.NoError(t, err)
+	_, err = c.IsEmpty()
+	assert.ErrorIs(t, err, ErrArbitraryEquality)
+	_, err = c.Bounds()
+	assert.ErrorIs(t, err, ErrArbitraryEquality)
+}
+
+func TestPEP440SpecifierCanonical(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"~=1.4.2", ">=1.4.2 <1.5.dev0"},
+		{"==1.4.*", ">=1.4.dev0 <1.5.dev0"},
+		{"==1.4", ">=1.4 <1.4.post0.dev0"},
+		{"<=1.4", "<1.4.post0.dev0"},
+		{"<1.4", "<1.4.dev0"},
+		{">1.4", ">1.4.post*"},
+		{"!=1.4.*", "<1.4.dev0 || >=1.5.dev0"},
+		{">=1.0,<2", ">=1.0 <2.dev0"},
+		{"===1.0.0", "===1.0.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewPEP440Specifier(tc.spec)
+		if assert.NoError(t, err, tc.spec) {
+			assert.Equal(t, tc.expected, c.Canonical())
+			assert.Equal(t, tc.spec, c.String())
+		}
+	}
+}
+
+func TestNewPEP440SpecifierError(t *testing.T) {
+	tests := []struct {
+		spec   string
+		offset int
+		token  string
+		err    error
+	}{
+		{"1.0", 0, "1.0", ErrInvalidConstraint},
+		{">=1.0,,<2", 6, "", ErrInvalidConstraint},
+		{">=1.0, ~=2", 9, "2", ErrInvalidConstraint},
+		{">=1.0, >=1.*", 9, "1.*", ErrInvalidConstraint},
+		{"==1.0a1.*", 2, "1.0a1.*", ErrInvalidConstraint},
+		{"==1.0+local.*", 2, "1.0+local.*", ErrInvalidConstraint},
+		{"<=1.0+local", 2, "1.0+local", ErrInvalidConstraint},
+		{">=1.0, <2.x", 8, "2.x", ErrInvalidPEP440},
+	}
+
+	for _, tc := range tests {
+		_, err := NewPEP440Specifier(tc.spec)
+		var pe *ParseError
+		if assert.True(t, errors.As(err, &pe), tc.spec) {
+			assert.Equal(t, tc.spec, pe.Input)
+			assert.Equal(t, tc.offset, pe.Offset, tc.spec)
+			assert.Equal(t, tc.token, pe.Token, tc.spec)
+			assert.True(t, errors.Is(err, tc.err), tc.spec)
+		}
+	}
+}
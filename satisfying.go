@@ -0,0 +1,86 @@
+package vc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxSatisfying returns the highest version of vs that satisfies the
+// constraints, or nil if none does. Of equal versions, the first one wins.
+func MaxSatisfying(c *Constraints, vs []Comparable) Comparable {
+	return pickSatisfying(c, vs, 1)
+}
+
+// MinSatisfying returns the lowest version of vs that satisfies the
+// constraints, or nil if none does. Of equal versions, the first one wins.
+func MinSatisfying(c *Constraints, vs []Comparable) Comparable {
+	return pickSatisfying(c, vs, -1)
+}
+
+// Filter returns the versions of vs that satisfy the constraints, in the
+// order they are given.
+func Filter(c *Constraints, vs []Comparable) []Comparable {
+	var result []Comparable
+	for _, v := range vs {
+		if v != nil && c.Check(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// MaxSatisfyingStrings is like MaxSatisfying, but parses the versions with
+// the version parser of the constraints. Versions that fail to parse are
+// skipped and reported in the returned error, which joins one error per
+// failed item.
+func MaxSatisfyingStrings(c *Constraints, vs []string) (Comparable, error) {
+	parsed, err := c.parseVersions(vs)
+	return MaxSatisfying(c, parsed), err
+}
+
+// MinSatisfyingStrings is like MinSatisfying, but parses the versions with
+// the version parser of the constraints. Versions that fail to parse are
+// skipped and reported in the returned error.
+func MinSatisfyingStrings(c *Constraints, vs []string) (Comparable, error) {
+	parsed, err := c.parseVersions(vs)
+	return MinSatisfying(c, parsed), err
+}
+
+// FilterStrings is like Filter, but parses the versions with the version
+// parser of the constraints. Versions that fail to parse are skipped and
+// reported in the returned error.
+func FilterStrings(c *Constraints, vs []string) ([]Comparable, error) {
+	parsed, err := c.parseVersions(vs)
+	return Filter(c, parsed), err
+}
+
+// pickSatisfying returns the satisfying version of vs that compares to the
+// others with the sign of dir.
+func pickSatisfying(c *Constraints, vs []Comparable, dir int) Comparable {
+	var best Comparable
+	for _, v := range vs {
+		if v == nil || !c.Check(v) {
+			continue
+		}
+		if best == nil || Compare(v, best) == dir {
+			best = v
+		}
+	}
+	return best
+}
+
+// parseVersions parses vs with the version parser of c, keeping the versions
+// that parse and joining the errors of those that do not.
+func (c *Constraints) parseVersions(vs []string) ([]Comparable, error) {
+	parsed := make([]Comparable, 0, len(vs))
+	var errs []error
+	for k, v := range vs {
+		ver, err := c.newfn(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", k, err))
+			continue
+		}
+		parsed = append(parsed, ver)
+	}
+	return parsed, errors.Join(errs...)
+}
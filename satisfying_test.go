@@ -0,0 +1,77 @@
+package vc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatisfying(t *testing.T) {
+	tags := []string{"1.2.3", "2.0.0", "1.5.0", "1.10.1", "0.9.0", "1.5.0-beta", "3.0.0"}
+	tests := []struct {
+		con      string
+		max      string
+		min      string
+		filtered []string
+	}{
+		{"^1.2", "1.10.1", "1.2.3", []string{"1.2.3", "1.5.0", "1.10.1", "1.5.0-beta"}},
+		{"~1.5", "1.5.0", "1.5.0", []string{"1.5.0"}},
+		{">=2 || <1", "3.0.0", "0.9.0", []string{"2.0.0", "0.9.0", "3.0.0"}},
+		{"^4", "", "", nil},
+	}
+
+	vs := make([]Comparable, 0, len(tags))
+	for _, tag := range tags {
+		v, err := NewSemverStr(tag)
+		assert.NoError(t, err)
+		vs = append(vs, v)
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		assert.Equal(t, tc.max, versionString(MaxSatisfying(c, vs)), tc.con)
+		assert.Equal(t, tc.min, versionString(MinSatisfying(c, vs)), tc.con)
+		var filtered []string
+		for _, v := range Filter(c, vs) {
+			filtered = append(filtered, versionString(v))
+		}
+		assert.Equal(t, tc.filtered, filtered, tc.con)
+
+		got, err := MaxSatisfyingStrings(c, tags)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.max, versionString(got), tc.con)
+	}
+}
+
+func TestSatisfyingStrings(t *testing.T) {
+	c := mustSemverConstraint(t, "^1.2")
+	tags := []string{"1.2.3", "latest", "1.4.0", "v1.x", "2.0.0"}
+
+	max, err := MaxSatisfyingStrings(c, tags)
+	assert.Equal(t, "1.4.0", versionString(max))
+	assert.ErrorIs(t, err, ErrInvalidSemVer)
+	assert.Contains(t, err.Error(), "item 1:")
+	assert.Contains(t, err.Error(), "item 3:")
+
+	min, err := MinSatisfyingStrings(c, tags)
+	assert.Equal(t, "1.2.3", versionString(min))
+	assert.Error(t, err)
+
+	filtered, err := FilterStrings(c, tags)
+	assert.Equal(t, 2, len(filtered))
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "latest", pe.Input)
+
+	filtered, err = FilterStrings(c, []string{"1.3.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(filtered))
+}
+
+func versionString(v Comparable) string {
+	if v == nil {
+		return ""
+	}
+	return formatVersion(v)
+}
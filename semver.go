@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -37,10 +36,17 @@ type Semver struct {
 // NewSemverStr parses a given version and returns an instance of Semver or
 // an error if unable to parse the version. If the version is SemVer-ish it
 // attempts to convert it to Semver.
+//
+// Parse failures are reported as a *ParseError wrapping ErrInvalidSemVer,
+// ErrSegmentStartsZero, ErrInvalidPrerelease or ErrInvalidMetadata.
 func NewSemverStr(ver string) (*Semver, error) {
 	m := versionRegex.FindStringSubmatch(ver)
 	if m == nil {
-		return nil, ErrInvalidSemVer
+		offset, reason := locateSyntaxError(ver, true, true)
+		if offset < 0 {
+			offset, reason = 0, "not a semantic version"
+		}
+		return nil, newSyntaxError(ErrInvalidSemVer, ver, offset, reason)
 	}
 
 	sv := &Semver{
@@ -49,24 +55,27 @@ func NewSemverStr(ver string) (*Semver, error) {
 		original: ver,
 	}
 
+	// Offset of the major version, after the optional v prefix
+	start := len(ver) - len(strings.TrimPrefix(ver, "v"))
+
 	var err error
-	sv.major, err = strconv.ParseUint(m[1], 10, 64)
+	sv.major, err = parseSegment(ErrInvalidSemVer, ver, m[1], start)
 	if err != nil {
-		return nil, fmt.Errorf("parsing version segment: %s", err)
+		return nil, err
 	}
 	if m[2] != "" {
-		sv.minor, err = strconv.ParseUint(strings.TrimPrefix(m[2], "."), 10, 64)
+		sv.minor, err = parseSegment(ErrInvalidSemVer, ver, strings.TrimPrefix(m[2], "."), start+len(m[1])+1)
 		if err != nil {
-			return nil, fmt.Errorf("parsing version segment: %s", err)
+			return nil, err
 		}
 	} else {
 		sv.minor = 0
 	}
 
 	if m[3] != "" {
-		sv.patch, err = strconv.ParseUint(strings.TrimPrefix(m[3], "."), 10, 64)
+		sv.patch, err = parseSegment(ErrInvalidSemVer, ver, strings.TrimPrefix(m[3], "."), start+len(m[1])+len(m[2])+1)
 		if err != nil {
-			return nil, fmt.Errorf("parsing version segment: %s", err)
+			return nil, err
 		}
 	} else {
 		sv.patch = 0
@@ -75,15 +84,17 @@ func NewSemverStr(ver string) (*Semver, error) {
 	// Perform some basic due diligence on the extra parts to ensure they are
 	// valid.
 
+	// Offset of the prerelease, after its - separator
+	preStart := start + len(m[1]) + len(m[2]) + len(m[3]) + 1
 	if sv.pre != "" {
 		if err = validatePrerelease(sv.pre); err != nil {
-			return nil, err
+			return nil, invalidPart(err, ver, sv.pre, preStart)
 		}
 	}
 
 	if sv.metadata != "" {
 		if err = validateMetadata(sv.metadata); err != nil {
-			return nil, err
+			return nil, invalidPart(err, ver, sv.metadata, len(ver)-len(sv.metadata))
 		}
 	}
 
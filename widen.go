@@ -0,0 +1,324 @@
+package vc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WidenStrategy decides how Widen rewrites constraints to admit a version.
+type WidenStrategy int
+
+const (
+	// StrategyReplace replaces the constraints with a single range starting
+	// at the version, e.g. ^1.4 with 2.0.0 gives ^2.0.
+	StrategyReplace WidenStrategy = iota
+	// StrategyWiden keeps the constraints and appends an OR group starting
+	// at the version, e.g. ^1.4 with 2.0.0 gives ^1.4 || ^2.0. Constraints
+	// already admitting the version are kept as they are.
+	StrategyWiden
+	// StrategyBumpLower raises the lower bounds of every group to the
+	// version, e.g. >=1.4 <3 with 2.1.0 gives >=2.1 <3.
+	StrategyBumpLower
+)
+
+// String returns the name of the strategy.
+func (s WidenStrategy) String() string {
+	switch s {
+	case StrategyReplace:
+		return "replace"
+	case StrategyWiden:
+		return "widen"
+	case StrategyBumpLower:
+		return "bump-lower"
+	default:
+		return "unknown"
+	}
+}
+
+// Widen rewrites constraints to admit a version, e.g. a new major release.
+//
+// The rewritten terms keep the operator style of the original, caret,
+// tilde, x-range, hyphen range or comparison, and its precision: ^1.4 gives
+// ^2.0 rather than ^2.0.0, and 1.x gives 2.x. Upper bounds and exclusions
+// that the version satisfies are kept, the others are dropped.
+// StrategyReplace and StrategyWiden derive the new range from the OR group
+// with the highest lower bound not above the version. StrategyBumpLower
+// keeps the groups whose lower bound is already above the version. Terms
+// other than comparisons and hyphen ranges, such as negations and aliases,
+// are written as the comparators they stand for in the group.
+//
+// The result always admits the version: if the rewritten groups do not,
+// e.g. >3 bumped to 2.0.0, the new range is added as an OR group as
+// StrategyWiden does, >3 || >=2. Constraints that cannot admit it, such as
+// those excluding prereleases for a prerelease, return ErrInvalidConstraint.
+//
+// The result is written in the dialect of c and parsed with its version
+// parser and options. Constraints of NewPEP440Specifier, NewMavenRange and
+// NewDebianRelation cannot be written back in their syntax and return
+// ErrUnsupportedSyntax.
+func Widen(c *Constraints, v Comparable, strategy WidenStrategy) (*Constraints, error) {
+	if c.syntax != "" {
+		return nil, fmt.Errorf("%w: %s %q", ErrUnsupportedSyntax, c.syntax, c.String())
+	}
+	if len(c.constraints) == 0 {
+		return nil, fmt.Errorf("%w: %q matches no version to widen", ErrInvalidConstraint, c.String())
+	}
+	d := newOptions(c.opts).dialect
+	next := joinTerms(bumpGroup(nearestGroup(c.constraints, v), v), d)
+
+	var expr string
+	switch strategy {
+	case StrategyReplace:
+		expr = next
+	case StrategyWiden:
+		if c.Check(v) {
+			expr = c.String()
+			break
+		}
+		expr = c.String() + " || " + next
+	case StrategyBumpLower:
+		var groups []string
+		seen := make(map[string]bool)
+		for _, g := range c.constraints {
+			var bumped string
+			if floor := groupFloor(g); floor != nil && Compare(v, floor) < 0 {
+				bumped = joinTerms(groupTerms(g), d)
+			} else {
+				bumped = joinTerms(bumpGroup(g, v), d)
+			}
+			if !seen[bumped] {
+				seen[bumped] = true
+				groups = append(groups, bumped)
+			}
+		}
+		expr = strings.Join(groups, " || ")
+	default:
+		return nil, fmt.Errorf("unknown widen strategy %d", strategy)
+	}
+
+	opts := append(append([]Option(nil), c.opts...), WithPrereleasePolicy(c.policy))
+	result, err := NewConstraint(expr, c.newfn, opts...)
+	if err != nil || result.Check(v) {
+		return result, err
+	}
+	if strategy == StrategyBumpLower {
+		if result, err = NewConstraint(expr+" || "+next, c.newfn, opts...); err != nil || result.Check(v) {
+			return result, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %q cannot be widened to admit %s", ErrInvalidConstraint, c.String(),
+		formatVersion(v))
+}
+
+// bumpGroup returns the terms of a group with its lower bounds moved to v.
+// Terms that only bound the group from above, or exclude versions, are kept
+// if v satisfies them.
+func bumpGroup(group []*constraint, v Comparable) []string {
+	var result []string
+	for _, t := range sourceTerms(group) {
+		src := t[0].source()
+		if lo, hi, ok := strings.Cut(src, OperatorRange); ok {
+			// The upper end of a hyphen range is kept if v satisfies it.
+			var upper []*constraint
+			for _, a := range t {
+				if a.operator == OperatorLt || a.operator == OperatorLte {
+					upper = append(upper, a)
+				}
+			}
+			lo = anchorVersion(lo, v)
+			if admits(upper, v) {
+				result = append(result, lo+OperatorRange+hi)
+			} else {
+				result = append(result, OperatorGte+lo)
+			}
+			continue
+		}
+		op, ver, ok := comparison(src)
+		switch {
+		case ok && (op == "" || op == OperatorEq || op == OperatorCaret || op == OperatorTilde ||
+			op == OperatorGte || op == OperatorGt):
+			if op == OperatorGt {
+				op = OperatorGte
+			}
+			result = append(result, op+anchorVersion(ver, v))
+		case admits(t, v):
+			result = append(result, writeTerm(t))
+		}
+	}
+	if len(result) == 0 {
+		return []string{OperatorGte + formatVersion(v)}
+	}
+	return result
+}
+
+// admits reports whether v satisfies all comparators.
+func admits(cs []*constraint, v Comparable) bool {
+	for _, a := range cs {
+		if !operatorsMap[a.operator](v, a) {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceTerms splits a group into its terms as written, so the two ends of
+// a hyphen range form a single term.
+func sourceTerms(group []*constraint) [][]*constraint {
+	var result [][]*constraint
+	for _, t := range terms(group) {
+		n := len(result)
+		if n > 0 && t[0].term != "" && result[n-1][0].term == t[0].term {
+			result[n-1] = append(result[n-1], t...)
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// groupTerms returns the terms of a group, see writeTerm.
+func groupTerms(group []*constraint) []string {
+	var result []string
+	for _, t := range sourceTerms(group) {
+		result = append(result, writeTerm(t))
+	}
+	return result
+}
+
+// writeTerm writes a term as written if it is a single comparison or a
+// hyphen range. Other terms are written as their comparators, as the text of
+// a negation or an alias may stand for several groups: !(^1) is <1.0.0 in
+// one group and >=2.0.0 in another.
+func writeTerm(t []*constraint) string {
+	src := t[0].source()
+	if _, _, ok := comparison(src); ok || strings.Contains(src, OperatorRange) {
+		return src
+	}
+	parts := make([]string, 0, len(t))
+	for _, v := range t {
+		parts = append(parts, v.canonical())
+	}
+	return strings.Join(parts, " ")
+}
+
+// joinTerms writes the terms of a group in the syntax of a dialect.
+func joinTerms(ts []string, d Dialect) string {
+	if len(ts) == 0 {
+		return VersionAll
+	}
+	if d == DialectCargo {
+		return strings.Join(ts, ", ")
+	}
+	return strings.Join(ts, " ")
+}
+
+// groupFloor returns the highest lower bound of a group, nil if the group
+// has none.
+func groupFloor(group []*constraint) Comparable {
+	var floor Comparable
+	for _, v := range group {
+		switch v.operator {
+		case OperatorGte, OperatorGt, OperatorEq:
+			if floor == nil || Compare(v.com, floor) > 0 {
+				floor = v.com
+			}
+		}
+	}
+	return floor
+}
+
+// nearestGroup returns the group a new range starting at v is derived from:
+// the last of the groups with the highest lower bound not above v, or the
+// group with the lowest lower bound if v is below all of them.
+func nearestGroup(groups [][]*constraint, v Comparable) []*constraint {
+	// below orders lower bounds, a group without one comes first.
+	below := func(a, b Comparable) bool {
+		return b != nil && (a == nil || Compare(a, b) < 0)
+	}
+	best := -1
+	var bestFloor Comparable
+	for k, g := range groups {
+		floor := groupFloor(g)
+		if floor != nil && Compare(floor, v) > 0 {
+			continue
+		}
+		if best < 0 || !below(floor, bestFloor) {
+			best, bestFloor = k, floor
+		}
+	}
+	if best >= 0 {
+		return groups[best]
+	}
+	for k, g := range groups {
+		if floor := groupFloor(g); best < 0 || below(floor, bestFloor) {
+			best, bestFloor = k, floor
+		}
+	}
+	return groups[best]
+}
+
+// comparison splits a term into its operator and version if it is a single
+// comparison, rather than e.g. a negated expression or an alias.
+func comparison(src string) (string, string, bool) {
+	m := findConstraintRegex.FindStringSubmatch(src)
+	if m == nil {
+		return "", "", false
+	}
+	ver := strings.TrimPrefix(m[2], "v")
+	if ver == "" || !strings.ContainsAny(ver[:1], "0123456789xX*") {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// anchorVersion renders v in the shape of ver: the same number of parts,
+// wildcards after the same position and the same "v" prefix. Zero parts
+// beyond the precision of ver are omitted, e.g. 2.0.0 in the shape of 1.4
+// is 2.0, while 2.0.3 is 2.0.3.
+func anchorVersion(ver string, v Comparable) string {
+	prefix := ""
+	if strings.HasPrefix(ver, "v") {
+		prefix, ver = "v", ver[1:]
+	}
+	release := ver
+	if i := strings.IndexAny(release, "-+"); i >= 0 {
+		release = release[:i]
+	}
+	parts := strings.Split(release, ".")
+	nums := []uint64{v.Major(), v.Minor(), v.Patch()}
+
+	if hasWildcard(ver) {
+		for k, p := range parts {
+			if p == VersionAll || p == VersionX || p == "X" {
+				break
+			}
+			if k < len(nums) {
+				parts[k] = fmt.Sprint(nums[k])
+			}
+		}
+		return prefix + strings.Join(parts, ".")
+	}
+
+	n := len(parts)
+	if v.Prerelease() != "" {
+		n = len(nums)
+	}
+	for k := len(nums) - 1; k >= n; k-- {
+		if nums[k] != 0 {
+			n = k + 1
+			break
+		}
+	}
+	if n > len(nums) {
+		n = len(nums)
+	}
+	out := make([]string, 0, n)
+	for _, num := range nums[:n] {
+		out = append(out, fmt.Sprint(num))
+	}
+	s := prefix + strings.Join(out, ".")
+	if v.Prerelease() != "" {
+		s += "-" + v.Prerelease()
+	}
+	return s
+}
@@ -0,0 +1,183 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWiden(t *testing.T) {
+	tests := []struct {
+		con      string
+		ver      string
+		strategy WidenStrategy
+		expected string
+	}{
+		{"^1.4", "2.0.0", StrategyReplace, "^2.0"},
+		{"^1.4", "2.0.0", StrategyWiden, "^1.4 || ^2.0"},
+		{"^1.4", "2.0.0", StrategyBumpLower, "^2.0"},
+		{"^1.4.2", "2.0.0", StrategyWiden, "^1.4.2 || ^2.0.0"},
+		{"^1", "2.3.0", StrategyWiden, "^1 || ^2.3"},
+		{"~1.4", "1.6.2", StrategyReplace, "~1.6.2"},
+		{"1.4.x", "2.1.0", StrategyWiden, "1.4.x || 2.1.x"},
+		{"1.x", "2.1.0", StrategyReplace, "2.x"},
+		{"v1.X.X", "3.0.0", StrategyReplace, "v3.X.X"},
+		{"=1.4.2", "2.0.0", StrategyReplace, "=2.0.0"},
+		{">=1.4 <3", "2.1.0", StrategyBumpLower, ">=2.1 <3"},
+		{">=1.4 <2", "2.1.0", StrategyWiden, ">=1.4 <2 || >=2.1"},
+		{">1.4.0 !=1.5.0 !=2.0.0", "2.0.0", StrategyBumpLower, ">=2.0.0 !=1.5.0"},
+		{"^1.4", "1.5.0", StrategyWiden, "^1.4"},
+		{"^1.4 || ^2", "2.5.0", StrategyBumpLower, "^2.5"},
+		{"^1.4 || ~3.1", "4.0.0", StrategyReplace, "~4.0"},
+		{"^1.2 && !(=1.4.5 || =1.4.6)", "2.0.0", StrategyReplace, "^2.0 !=1.4.5 !=1.4.6"},
+		{"^1.4", "3.0.0-rc.1", StrategyWiden, "^1.4 || ^3.0.0-rc.1"},
+		{"*", "2.0.0", StrategyBumpLower, ">=2.0.0"},
+		{"~3.1 || ^1.4", "2.0.0", StrategyReplace, "^2.0"},
+		{"~3.1 || ^1.4", "2.0.0", StrategyWiden, "~3.1 || ^1.4 || ^2.0"},
+		{"~3.1 || ^1.4", "2.0.0", StrategyBumpLower, "~3.1 || ^2.0"},
+		{"1.0 - 1.5", "1.5.0", StrategyReplace, "1.5 - 1.5"},
+		{"1.0 - 1.5", "2.0.0", StrategyWiden, "1.0 - 1.5 || >=2.0"},
+		{"1.0.0 - 1.5.0 !=1.2.0", "1.3.0", StrategyBumpLower, "1.3.0 - 1.5.0 !=1.2.0"},
+	}
+
+	for _, tc := range tests {
+		c := mustSemverConstraint(t, tc.con)
+		v, err := NewSemverStr(tc.ver)
+		assert.NoError(t, err)
+		got, err := Widen(c, v, tc.strategy)
+		assert.NoError(t, err, "%s %s %s", tc.con, tc.ver, tc.strategy)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.expected, got.String(), "%s %s %s", tc.con, tc.ver, tc.strategy)
+		assert.True(t, got.Check(v), "%s %s %s", tc.con, tc.ver, tc.strategy)
+	}
+}
+
+func TestWidenDialects(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		con      string
+		ver      string
+		strategy WidenStrategy
+		expected string
+	}{
+		{DialectNPM, "^1.4", "2.0.0", StrategyWiden, "^1.4 || ^2.0"},
+		{DialectNPM, ">= 1.2 <1.5", "2.1.0", StrategyReplace, ">=2.1"},
+		{DialectNPM, "~> 1.2", "2.0.0", StrategyReplace, "~2.0"},
+		{DialectNPM, "1.2", "2.0.0", StrategyWiden, "1.2 || 2.0"},
+		{DialectNPM, "1.0 - 1.5", "1.2.0", StrategyBumpLower, "1.2 - 1.5"},
+		{DialectCargo, ">=1.2, <1.5", "2.0.0", StrategyWiden, ">=1.2, <1.5 || >=2.0"},
+		{DialectCargo, ">=1.2, <3", "2.0.0", StrategyBumpLower, ">=2.0, <3"},
+		{DialectCargo, "1.2.3", "2.0.0", StrategyReplace, "2.0.0"},
+		{DialectCargo, "~1.2", "1.4.0", StrategyReplace, "~1.4"},
+		{DialectCargo, "1.0 - 1.5", "2.0.0", StrategyReplace, ">=2.0"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.con, func(s string) (Comparable, error) {
+			return NewSemverStr(s)
+		}, WithDialect(tc.dialect))
+		assert.NoError(t, err, tc.con)
+		v, _ := NewSemverStr(tc.ver)
+		got, err := Widen(c, v, tc.strategy)
+		if assert.NoError(t, err, "%s %s %s", tc.con, tc.ver, tc.strategy) {
+			assert.Equal(t, tc.expected, got.String(), "%s %s %s", tc.con, tc.ver, tc.strategy)
+			assert.True(t, got.Check(v), "%s %s %s", tc.con, tc.ver, tc.strategy)
+		}
+	}
+}
+
+func TestWidenBelowLowerBound(t *testing.T) {
+	v, _ := NewSemverStr("1.5.0")
+	got, err := Widen(mustSemverConstraint(t, "^2.0"), v, StrategyBumpLower)
+	assert.NoError(t, err)
+	assert.Equal(t, "^2.0 || ^1.5", got.String())
+	assert.True(t, got.Check(v))
+
+	got, err = Widen(mustSemverConstraint(t, "^1.2 || ^2.0"), v, StrategyBumpLower)
+	assert.NoError(t, err)
+	assert.Equal(t, "^1.5 || ^2.0", got.String())
+	assert.True(t, got.Check(v))
+
+	v, _ = NewSemverStr("2.0.0")
+	got, err = Widen(mustSemverConstraint(t, ">3"), v, StrategyBumpLower)
+	assert.NoError(t, err)
+	assert.Equal(t, ">3 || >=2", got.String())
+	assert.True(t, got.Check(v))
+}
+
+func TestWidenNegation(t *testing.T) {
+	tests := []struct {
+		strategy WidenStrategy
+		expected string
+	}{
+		{StrategyReplace, ">=1.5.0"},
+		{StrategyWiden, "!(^1) || >=1.5.0"},
+		{StrategyBumpLower, ">=1.5.0 || >=2.0.0"},
+	}
+
+	v, _ := NewSemverStr("1.5.0")
+	for _, tc := range tests {
+		got, err := Widen(mustSemverConstraint(t, "!(^1)"), v, tc.strategy)
+		if assert.NoError(t, err, tc.strategy) {
+			assert.Equal(t, tc.expected, got.String(), tc.strategy)
+			assert.True(t, got.Check(v), tc.strategy)
+		}
+	}
+}
+
+func TestWidenOptions(t *testing.T) {
+	c, err := NewConstraint("lts", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithAliases(map[string]string{"lts": "^1.22"}), WithPrereleasePolicy(PrereleaseNPM))
+	assert.NoError(t, err)
+
+	v, _ := NewSemverStr("2.0.0")
+	got, err := Widen(c, v, StrategyWiden)
+	assert.NoError(t, err)
+	assert.Equal(t, "lts || >=2.0.0", got.String())
+	assert.True(t, got.Check(v))
+
+	ok, err := got.CheckString("2.1.0-beta")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestWidenErrors(t *testing.T) {
+	v, _ := NewSemverStr("2.0.0")
+	none, err := mustSemverConstraint(t, "^1").Intersect(mustSemverConstraint(t, "^2"))
+	assert.NoError(t, err)
+	_, err = Widen(none, v, StrategyWiden)
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+
+	exclude, err := NewConstraint("^1", func(s string) (Comparable, error) {
+		return NewSemverStr(s)
+	}, WithPrereleasePolicy(PrereleaseExclude))
+	assert.NoError(t, err)
+	rc, _ := NewSemverStr("2.0.0-rc.1")
+	_, err = Widen(exclude, rc, StrategyBumpLower)
+	assert.ErrorIs(t, err, ErrInvalidConstraint)
+
+	spec, err := NewPEP440Specifier("~=1.4")
+	assert.NoError(t, err)
+	pv, _ := NewPEP440Str("2.0")
+	_, err = Widen(spec, pv, StrategyWiden)
+	assert.ErrorIs(t, err, ErrUnsupportedSyntax)
+
+	rng, err := NewMavenRange("[1.0,2.0)")
+	assert.NoError(t, err)
+	mv, _ := NewMavenVersionStr("2.0")
+	_, err = Widen(rng, mv, StrategyReplace)
+	assert.ErrorIs(t, err, ErrUnsupportedSyntax)
+
+	rel, err := NewDebianRelation(">= 1.0, << 2.0")
+	assert.NoError(t, err)
+	dv, _ := NewDebianVersionStr("2.0")
+	_, err = Widen(rel, dv, StrategyBumpLower)
+	assert.ErrorIs(t, err, ErrUnsupportedSyntax)
+
+	_, err = Widen(mustSemverConstraint(t, "^1"), v, WidenStrategy(9))
+	assert.Error(t, err)
+	assert.Equal(t, "bump-lower", StrategyBumpLower.String())
+}